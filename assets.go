@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"text/tabwriter"
+)
+
+// assetConflictPolicy decides what happens when a release already has an
+// asset with the same name as one being uploaded
+type assetConflictPolicy string
+
+const (
+	assetConflictFail    assetConflictPolicy = "fail"
+	assetConflictSkip    assetConflictPolicy = "skip"
+	assetConflictReplace assetConflictPolicy = "replace"
+)
+
+type assetUploadStatus string
+
+const (
+	assetUploaded assetUploadStatus = "uploaded"
+	assetSkipped  assetUploadStatus = "skipped"
+	assetFailed   assetUploadStatus = "failed"
+)
+
+type assetUploadResult struct {
+	Path   string
+	Name   string
+	Status assetUploadStatus
+	Reason string
+
+	output bytes.Buffer
+	done   chan struct{}
+}
+
+type assetUploadOptions struct {
+	OnConflict  assetConflictPolicy
+	CompareSize bool
+	Parallel    int
+	Debug       bool
+	// NamePrefix is put in front of the name of every uploaded asset, which
+	// lets rolling releases upload under temporary names
+	NamePrefix string
+}
+
+type assetConflictError struct {
+	filename string
+}
+
+type assetUploadFailedError struct {
+	failed int
+	total  int
+}
+
+// newAssetUploadOptions reads the upload flags. Commands without an
+// --on-conflict flag, like rolling-release, get the fail policy and set their
+// own.
+func newAssetUploadOptions(ctx *cli.Context) (*assetUploadOptions, error) {
+	onConflict := assetConflictFail
+
+	if value := ctx.String("on-conflict"); value != "" {
+		policy, err := parseAssetConflictPolicy(value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		onConflict = policy
+	}
+
+	parallel := ctx.Int("parallel")
+
+	if parallel < 1 {
+		reason := fmt.Sprintf("expected at least 1 but found %d", parallel)
+		return nil, &badArgumentError{argument: "--parallel", reason: reason}
+	}
+
+	compareSize := ctx.Bool("compare-size")
+
+	if compareSize && onConflict != assetConflictSkip {
+		return nil, &badArgumentError{argument: "--compare-size", reason: "can only be used with --on-conflict=skip"}
+	}
+
+	options := &assetUploadOptions{
+		OnConflict:  onConflict,
+		CompareSize: compareSize,
+		Parallel:    parallel,
+		Debug:       ctx.GlobalBool("debug"),
+	}
+
+	return options, nil
+}
+
+func parseAssetConflictPolicy(value string) (assetConflictPolicy, error) {
+	switch policy := assetConflictPolicy(value); policy {
+	case assetConflictFail, assetConflictSkip, assetConflictReplace:
+		return policy, nil
+	}
+
+	reason := fmt.Sprintf("expected one of fail, skip or replace but found \"%s\"", value)
+	return "", &badArgumentError{argument: "--on-conflict", reason: reason}
+}
+
+// uploadAssets uploads each of the assets to the release and returns the
+// outcome for every one of them, in the same order as assets.
+//
+// Up to options.Parallel assets are uploaded at the same time. Whatever an
+// upload prints is buffered and written out in the order of assets so the
+// output is the same no matter how the uploads interleave. If an upload
+// fails in a way that means the others can't succeed either, like a bad
+// token or the release being deleted, the remaining uploads are cancelled.
+func uploadAssets(netCtx context.Context, repo *gitHubRepo, releaseId int, assets []string, gitHubToken string, options *assetUploadOptions) []*assetUploadResult {
+	results := make([]*assetUploadResult, len(assets))
+
+	for i, assetPath := range assets {
+		results[i] = &assetUploadResult{
+			Path: assetPath,
+			Name: options.NamePrefix + path.Base(assetPath),
+			done: make(chan struct{}),
+		}
+	}
+
+	if len(assets) == 0 {
+		return results
+	}
+
+	existingAssets, err := repo.ListReleaseAssets(netCtx, releaseId, gitHubToken)
+
+	if err != nil {
+		for _, result := range results {
+			result.Status = assetFailed
+			result.Reason = fmt.Sprintf("could not list existing assets: %v", err)
+		}
+
+		return results
+	}
+
+	existingByName := make(map[string]*gitHubAsset)
+
+	for _, asset := range existingAssets {
+		existingByName[*asset.Name] = asset
+	}
+
+	uploadCtx, cancel := context.WithCancel(netCtx)
+	defer cancel()
+
+	var fatalLock sync.Mutex
+	var fatalErr error
+
+	jobs := make(chan *assetUploadResult)
+
+	workers := options.Parallel
+
+	if workers > len(assets) {
+		workers = len(assets)
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for result := range jobs {
+				fatalLock.Lock()
+				cancelledBy := fatalErr
+				fatalLock.Unlock()
+
+				if cancelledBy != nil {
+					result.Status = assetFailed
+					result.Reason = fmt.Sprintf("not attempted after earlier error: %v", cancelledBy)
+				} else {
+					err := uploadAssetAtPath(uploadCtx, repo, releaseId, result, existingByName[result.Name], gitHubToken, options)
+
+					if isFatalUploadError(err) {
+						fatalLock.Lock()
+						if fatalErr == nil {
+							fatalErr = err
+						}
+						fatalLock.Unlock()
+
+						cancel()
+					}
+				}
+
+				close(result.done)
+			}
+		}()
+	}
+
+	go func() {
+		for _, result := range results {
+			jobs <- result
+		}
+
+		close(jobs)
+	}()
+
+	for _, result := range results {
+		<-result.done
+		result.output.WriteTo(os.Stdout)
+	}
+
+	return results
+}
+
+// uploadAssetAtPath opens the file for result and uploads it, recording the
+// outcome on result
+func uploadAssetAtPath(netCtx context.Context, repo *gitHubRepo, releaseId int, result *assetUploadResult, existing *gitHubAsset, gitHubToken string, options *assetUploadOptions) error {
+	file, err := os.Open(result.Path)
+
+	if err != nil {
+		result.Status = assetFailed
+		result.Reason = err.Error()
+		return err
+	}
+
+	defer file.Close()
+
+	skipped, err := uploadAsset(netCtx, repo, releaseId, file, result.Name, existing, gitHubToken, options, &result.output)
+
+	switch {
+	case err != nil:
+		result.Status = assetFailed
+		result.Reason = err.Error()
+	case skipped:
+		result.Status = assetSkipped
+		result.Reason = "already exists"
+	default:
+		result.Status = assetUploaded
+	}
+
+	return err
+}
+
+// uploadAsset uploads a single file, applying the conflict policy when the
+// release already has an asset with the same name. It reports whether the
+// upload was skipped because of the policy.
+func uploadAsset(netCtx context.Context, repo *gitHubRepo, releaseId int, file *os.File, filename string, existing *gitHubAsset, gitHubToken string, options *assetUploadOptions, out io.Writer) (bool, error) {
+	if existing != nil {
+		switch options.OnConflict {
+		case assetConflictFail:
+			return false, &assetConflictError{filename: filename}
+		case assetConflictSkip:
+			if !options.CompareSize || sameAssetSize(file, existing) {
+				if options.Debug {
+					fmt.Fprintf(out, "Asset %s already exists. Skipping.\n", filename)
+				}
+
+				return true, nil
+			}
+
+			if options.Debug {
+				fmt.Fprintf(out, "Asset %s already exists with a different size\n", filename)
+			}
+		}
+
+		return false, replaceAsset(netCtx, repo, releaseId, file, filename, existing, gitHubToken, options, out)
+	}
+
+	if options.Debug {
+		fmt.Fprintf(out, "Uploading asset at %s as %s\n", file.Name(), filename)
+	}
+
+	return false, repo.UploadReleaseAsset(netCtx, releaseId, file, filename, gitHubToken)
+}
+
+// replaceAsset uploads a file in place of an existing asset. Like rolling
+// releases do, the file is uploaded under a temporary name first, so the
+// release keeps the existing asset if the upload fails, and only then is the
+// existing asset deleted and the new one renamed.
+func replaceAsset(netCtx context.Context, repo *gitHubRepo, releaseId int, file *os.File, filename string, existing *gitHubAsset, gitHubToken string, options *assetUploadOptions, out io.Writer) error {
+	prefix := fmt.Sprintf("tmp-%d-", *existing.ID)
+
+	if options.Debug {
+		fmt.Fprintf(out, "Uploading asset at %s as %s%s to replace %s\n", file.Name(), prefix, filename, filename)
+	}
+
+	err := repo.UploadReleaseAsset(netCtx, releaseId, file, prefix+filename, gitHubToken)
+
+	if err != nil {
+		cleanupErr := deleteRollingAssets(netCtx, repo, releaseId, prefix, gitHubToken)
+
+		if cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not delete the asset uploaded under a temporary name: %v\n", cleanupErr)
+		}
+
+		return err
+	}
+
+	if options.Debug {
+		fmt.Fprintf(out, "Deleting existing asset %s (id: %d)\n", filename, *existing.ID)
+	}
+
+	err = repo.DeleteReleaseAsset(netCtx, *existing.ID, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	assets, err := repo.ListReleaseAssets(netCtx, releaseId, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range assets {
+		if stringValue(asset.Name) == prefix+filename {
+			return repo.RenameReleaseAsset(netCtx, *asset.ID, filename, gitHubToken)
+		}
+	}
+
+	return fmt.Errorf("could not find the asset uploaded as %s%s to rename it", prefix, filename)
+}
+
+// isFatalUploadError reports whether err means that none of the remaining
+// uploads can succeed either
+func isFatalUploadError(err error) bool {
+	switch e := err.(type) {
+	case *github.TwoFactorAuthError:
+		return true
+	case *github.ErrorResponse:
+		if e.Response == nil {
+			return false
+		}
+
+		switch e.Response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+			return true
+		}
+	}
+
+	return false
+}
+
+// reportAssetUploads prints a summary of the upload results and returns an
+// error if any of the assets failed to upload
+func reportAssetUploads(results []*assetUploadResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	failed := 0
+
+	fmt.Println("Asset Upload Summary")
+	fmt.Println("====================")
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "ASSET\tSTATUS\tREASON")
+
+	for _, result := range results {
+		if result.Status == assetFailed {
+			failed++
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Name, result.Status, result.Reason)
+	}
+
+	writer.Flush()
+
+	if failed > 0 {
+		return &assetUploadFailedError{failed: failed, total: len(results)}
+	}
+
+	return nil
+}
+
+func sameAssetSize(file *os.File, existing *gitHubAsset) bool {
+	info, err := file.Stat()
+
+	if err != nil || existing.Size == nil {
+		return false
+	}
+
+	return info.Size() == int64(*existing.Size)
+}
+
+func (e *assetConflictError) Error() string {
+	message := fmt.Sprintf("The release already has an asset named \"%s\"; use --on-conflict to skip or replace it", e.filename)
+	return message
+}
+
+func (e *assetUploadFailedError) Error() string {
+	message := fmt.Sprintf("%d of %d assets failed to upload", e.failed, e.total)
+	return message
+}
+
+func (e *assetUploadFailedError) ExitCode() int {
+	return 74
+}
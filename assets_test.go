@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeReleaseAssets serves the assets of release 1, recording every change
+// made to them
+type fakeReleaseAssets struct {
+	lock        sync.Mutex
+	assets      []map[string]interface{}
+	changes     []string
+	nextId      int
+	failUploads bool
+}
+
+func TestParseAssetConflictPolicy(test *testing.T) {
+	policy, err := parseAssetConflictPolicy("replace")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if policy != assetConflictReplace {
+		test.Fatalf("Expected policy to be %s but got %s", assetConflictReplace, policy)
+	}
+
+	_, err = parseAssetConflictPolicy("overwrite")
+
+	if err == nil {
+		test.Fatalf("Expected an error for an unknown policy")
+	}
+}
+
+func TestReportAssetUploadsFailure(test *testing.T) {
+	results := []*assetUploadResult{
+		{Path: "dist/a.tar.gz", Name: "a.tar.gz", Status: assetUploaded},
+		{Path: "dist/b.tar.gz", Name: "b.tar.gz", Status: assetFailed, Reason: "server error"},
+	}
+
+	err := reportAssetUploads(results)
+
+	failedError, ok := err.(*assetUploadFailedError)
+
+	if !ok {
+		test.Fatalf("Expected an assetUploadFailedError but got %v", err)
+	}
+
+	if failedError.failed != 1 || failedError.total != 2 {
+		test.Fatalf("Expected 1 of 2 assets to fail but got %d of %d", failedError.failed, failedError.total)
+	}
+}
+
+func TestUploadAssetsCancelsAfterFatalError(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == "GET" {
+			writer.Write([]byte("[]"))
+			return
+		}
+
+		// The release was deleted while uploading
+		writer.WriteHeader(http.StatusNotFound)
+		writer.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "grease")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	var assets []string
+
+	for _, name := range []string{"a.tar.gz", "b.tar.gz", "c.tar.gz"} {
+		assetPath := filepath.Join(dir, name)
+		ioutil.WriteFile(assetPath, []byte(name), 0644)
+		assets = append(assets, assetPath)
+	}
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+	client.UploadURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+	options := &assetUploadOptions{OnConflict: assetConflictFail, Parallel: 1}
+
+	results := uploadAssets(context.Background(), repo, 1, assets, "token", options)
+
+	if len(results) != 3 {
+		test.Fatalf("Expected 3 results but got %d", len(results))
+	}
+
+	for i, result := range results {
+		if result.Name != filepath.Base(assets[i]) {
+			test.Fatalf("Expected result %d to be for %s but got %s", i, filepath.Base(assets[i]), result.Name)
+		}
+
+		if result.Status != assetFailed {
+			test.Fatalf("Expected %s to fail but got %s", result.Name, result.Status)
+		}
+	}
+
+	if !strings.HasPrefix(results[2].Reason, "not attempted") {
+		test.Fatalf("Expected remaining uploads to be cancelled but got: %s", results[2].Reason)
+	}
+}
+
+func (fake *fakeReleaseAssets) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	id, _ := strconv.Atoi(request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:])
+
+	switch request.Method {
+	case "GET":
+		json.NewEncoder(writer).Encode(fake.assets)
+	case "POST":
+		name := request.URL.Query().Get("name")
+		fake.changes = append(fake.changes, "upload "+name)
+
+		if fake.failUploads {
+			writer.WriteHeader(http.StatusUnprocessableEntity)
+			writer.Write([]byte(`{"message": "Validation Failed"}`))
+			return
+		}
+
+		contents, _ := ioutil.ReadAll(request.Body)
+		asset := map[string]interface{}{"id": fake.nextId, "name": name, "size": len(contents), "state": "uploaded"}
+		fake.nextId++
+		fake.assets = append(fake.assets, asset)
+		writer.WriteHeader(http.StatusCreated)
+		json.NewEncoder(writer).Encode(asset)
+	case "DELETE":
+		fake.changes = append(fake.changes, fmt.Sprintf("delete %d", id))
+
+		var kept []map[string]interface{}
+
+		for _, asset := range fake.assets {
+			if asset["id"] != id {
+				kept = append(kept, asset)
+			}
+		}
+
+		fake.assets = kept
+		writer.WriteHeader(http.StatusNoContent)
+	case "PATCH":
+		var edit map[string]interface{}
+		json.NewDecoder(request.Body).Decode(&edit)
+		fake.changes = append(fake.changes, fmt.Sprintf("rename %d to %s", id, edit["name"]))
+
+		for _, asset := range fake.assets {
+			if asset["id"] == id {
+				asset["name"] = edit["name"]
+				json.NewEncoder(writer).Encode(asset)
+			}
+		}
+	}
+}
+
+// uploadAssetTo uploads a file with the contents "contents" as a.txt to the
+// fake, where the release already has an a.txt of the given size
+func uploadAssetTo(test *testing.T, fake *fakeReleaseAssets, existingSize int, options *assetUploadOptions) (bool, error) {
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	existingId := 5
+	existingName := "a.txt"
+	fake.assets = []map[string]interface{}{{"id": existingId, "name": existingName, "size": existingSize, "state": "uploaded"}}
+	fake.nextId = 6
+
+	file, err := ioutil.TempFile("", "grease")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	file.WriteString("contents")
+	file.Seek(0, 0)
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+	client.UploadURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+	existing := &gitHubAsset{ID: &existingId, Name: &existingName, Size: &existingSize}
+
+	return uploadAsset(context.Background(), repo, 1, file, "a.txt", existing, "token", options, ioutil.Discard)
+}
+
+func TestUploadAssetSkip(test *testing.T) {
+	fake := &fakeReleaseAssets{}
+
+	skipped, err := uploadAssetTo(test, fake, 3, &assetUploadOptions{OnConflict: assetConflictSkip})
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if !skipped || len(fake.changes) != 0 {
+		test.Fatalf("Expected the existing asset to be left alone but got %v", fake.changes)
+	}
+}
+
+func TestUploadAssetSkipCompareSize(test *testing.T) {
+	fake := &fakeReleaseAssets{}
+	options := &assetUploadOptions{OnConflict: assetConflictSkip, CompareSize: true}
+
+	skipped, err := uploadAssetTo(test, fake, len("contents"), options)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if !skipped || len(fake.changes) != 0 {
+		test.Fatalf("Expected an asset of the same size to be left alone but got %v", fake.changes)
+	}
+
+	skipped, err = uploadAssetTo(test, fake, 3, options)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if skipped || len(fake.assets) != 1 || fake.assets[0]["name"] != "a.txt" || fake.assets[0]["id"] != 6 {
+		test.Fatalf("Expected an asset of a different size to be replaced but got %v", fake.changes)
+	}
+}
+
+func TestUploadAssetReplace(test *testing.T) {
+	fake := &fakeReleaseAssets{}
+
+	_, err := uploadAssetTo(test, fake, 3, &assetUploadOptions{OnConflict: assetConflictReplace})
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	expected := []string{"upload tmp-5-a.txt", "delete 5", "rename 6 to a.txt"}
+
+	if strings.Join(fake.changes, ", ") != strings.Join(expected, ", ") {
+		test.Fatalf("Expected %v but got %v", expected, fake.changes)
+	}
+}
+
+func TestUploadAssetReplaceKeepsExistingOnFailure(test *testing.T) {
+	fake := &fakeReleaseAssets{failUploads: true}
+
+	_, err := uploadAssetTo(test, fake, 3, &assetUploadOptions{OnConflict: assetConflictReplace})
+
+	if err == nil {
+		test.Fatalf("Expected the upload to fail")
+	}
+
+	if len(fake.assets) != 1 || fake.assets[0]["id"] != 5 {
+		test.Fatalf("Expected the existing asset to be kept but got %v", fake.changes)
+	}
+}
+
+func TestUploadAssetsCompareSizeRequiresSkip(test *testing.T) {
+	server := httptest.NewServer(newFakeGitHub())
+	defer server.Close()
+
+	output, status := runCommand(test, server, "upload-assets", "--compare-size", "--on-conflict", "replace", "timberio/grease", "v1.0.0", "dist/*")
+
+	if status != 64 {
+		test.Fatalf("Expected --compare-size without --on-conflict=skip to exit with 64 but got %d:\n%s", status, output)
+	}
+}
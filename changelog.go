@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// defaultChangelogPath is the changelog read by --notes-from-changelog when
+// no path is given
+const defaultChangelogPath = "CHANGELOG.md"
+
+// changelogHeadingPattern matches the release headings of a changelog in
+// the Keep a Changelog format, like "## [1.0.1] - 2017-08-24" or
+// "## [Unreleased]". The brackets are optional.
+var changelogHeadingPattern = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+
+// changelogLinkPattern matches the link reference definitions Keep a
+// Changelog puts at the bottom of the file, like
+// "[1.0.1]: https://github.com/timberio/grease/compare/v1.0.0...v1.0.1"
+var changelogLinkPattern = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+
+// optionalPathValue is a flag value that can be passed on its own, like a
+// boolean flag, or with a path, as in --notes-from-changelog=docs/CHANGES.md
+type optionalPathValue struct {
+	defaultPath string
+	path        string
+}
+
+type changelogSectionError struct {
+	path       string
+	version    string
+	unreleased bool
+	empty      bool
+}
+
+// readChangelogNotes returns the body of the section for tag in the
+// changelog at path
+func readChangelogNotes(path string, tag string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return "", &badArgumentError{argument: "--notes-from-changelog", reason: err.Error()}
+	}
+
+	return changelogSection(string(contents), path, tag)
+}
+
+// changelogSection finds the section of a Keep a Changelog formatted
+// changelog for the version named by tag, with or without a leading "v",
+// and returns its body without the heading
+func changelogSection(contents string, path string, tag string) (string, error) {
+	version := strings.TrimPrefix(tag, "v")
+	sections := make(map[string][]string)
+
+	var current string
+	var found bool
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := changelogHeadingPattern.FindStringSubmatch(line); matches != nil {
+			current = strings.ToLower(strings.TrimPrefix(matches[1], "v"))
+			found = found || current == strings.ToLower(version)
+			sections[current] = []string{}
+			continue
+		}
+
+		if current == "" || changelogLinkPattern.MatchString(line) {
+			continue
+		}
+
+		sections[current] = append(sections[current], line)
+	}
+
+	if !found {
+		unreleased := strings.TrimSpace(strings.Join(sections["unreleased"], "\n")) != ""
+		return "", &changelogSectionError{path: path, version: version, unreleased: unreleased}
+	}
+
+	body := strings.TrimSpace(strings.Join(sections[strings.ToLower(version)], "\n"))
+
+	if body == "" {
+		return "", &changelogSectionError{path: path, version: version, empty: true}
+	}
+
+	return body, nil
+}
+
+func (value *optionalPathValue) Set(path string) error {
+	if path == "true" {
+		path = value.defaultPath
+	}
+
+	value.path = path
+
+	return nil
+}
+
+func (value *optionalPathValue) String() string {
+	return value.path
+}
+
+// IsBoolFlag lets the flag be passed without a value
+func (value *optionalPathValue) IsBoolFlag() bool {
+	return true
+}
+
+func (e *changelogSectionError) Error() string {
+	if e.empty {
+		return fmt.Sprintf("The section for %s in %s has no release notes", e.version, e.path)
+	}
+
+	message := fmt.Sprintf("Could not find a section for %s in %s", e.version, e.path)
+
+	if e.unreleased {
+		message += "; the changes may still be listed under [Unreleased]"
+	}
+
+	return message
+}
+
+func (e *changelogSectionError) ExitCode() int {
+	return 65
+}
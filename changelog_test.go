@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+)
+
+const testChangelog = `# Change Log
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+### Added
+- Support for GitHub Enterprise
+
+## [1.0.1] - 2017-08-24
+### Fixed
+- Assets are uploaded with the right name
+
+## [1.0.0] - 2017-08-23
+### Added
+- Initial release
+
+[Unreleased]: https://github.com/timberio/grease/compare/v1.0.1...HEAD
+[1.0.1]: https://github.com/timberio/grease/compare/v1.0.0...v1.0.1
+[1.0.0]: https://github.com/timberio/grease/tree/v1.0.0
+`
+
+func TestChangelogSection(test *testing.T) {
+	expected := "### Fixed\n- Assets are uploaded with the right name"
+
+	notes, err := changelogSection(testChangelog, "CHANGELOG.md", "v1.0.1")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if notes != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, notes)
+	}
+
+	// The last section should not pick up the link definitions
+	expected = "### Added\n- Initial release"
+
+	notes, err = changelogSection(testChangelog, "CHANGELOG.md", "1.0.0")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if notes != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, notes)
+	}
+}
+
+func TestChangelogSectionStillUnreleased(test *testing.T) {
+	_, err := changelogSection(testChangelog, "CHANGELOG.md", "v1.1.0")
+
+	sectionError, ok := err.(*changelogSectionError)
+
+	if !ok {
+		test.Fatalf("Expected a changelogSectionError but got %v", err)
+	}
+
+	if !sectionError.unreleased {
+		test.Fatalf("Expected the error to point at the [Unreleased] section")
+	}
+}
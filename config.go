@@ -0,0 +1,398 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/urfave/cli.v1/altsrc"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultConfigFileName is the configuration file looked for in the working
+// directory when --config is not passed
+const defaultConfigFileName = ".grease.yml"
+
+// configFileMetadataKey is the key under which the loaded configuration file
+// is kept in the application's metadata for the commands to use
+const configFileMetadataKey = "config"
+
+/*
+Configuration files
+
+A configuration file supplies default values for flags so they don't have to
+be repeated on every invocation. Keys are flag names (without the leading
+dashes). Keys at the top level apply to every command that has a flag with that
+name, and keys nested under a command's name apply to that command only and
+take precedence:
+
+	assets: dist/*
+	github-token: ...
+	repo: timberio/grease
+	create-release:
+	  draft: true
+
+The special "repo" key lets commands be run without the REPO positional
+argument. Flags passed on the command line or set through their environment
+variable always win over the configuration file.
+
+Settings that don't fit in a flag, like the sections release notes generated
+from pull requests are grouped into, are read from their own top-level keys
+with decode.
+*/
+
+type configFile struct {
+	Path   string
+	Source altsrc.InputSourceContext
+}
+
+// loadConfigFile reads the configuration file named by --config or, failing
+// that, the default one in the working directory. It returns nil if there is
+// no configuration file to read.
+func loadConfigFile(ctx *cli.Context) (*configFile, error) {
+	path := ctx.GlobalString("config")
+
+	if path == "" {
+		_, err := os.Stat(defaultConfigFileName)
+
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		path = defaultConfigFileName
+	}
+
+	source, err := altsrc.NewYamlSourceFromFile(path)
+
+	if err != nil {
+		return nil, &badArgumentError{argument: "--config", reason: err.Error()}
+	}
+
+	return &configFile{Path: path, Source: source}, nil
+}
+
+// beforeApp loads the configuration file and applies it to the global flags
+func beforeApp(ctx *cli.Context) error {
+	config, err := loadConfigFile(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if config == nil {
+		return nil
+	}
+
+	ctx.App.Metadata[configFileMetadataKey] = config
+
+	sources, err := config.apply(ctx, "", ctx.App.Flags)
+
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("debug") {
+		fmt.Printf("Using configuration file %s\n", config.Path)
+		printFlagSources(sources)
+	}
+
+	return nil
+}
+
+// withConfigFile applies the configuration file to a command's flags before
+// running the command's own before function
+func withConfigFile(before cli.BeforeFunc) cli.BeforeFunc {
+	return func(ctx *cli.Context) error {
+		config := commandConfigFile(ctx)
+
+		if config != nil {
+			sources, err := config.apply(ctx, ctx.Command.Name, ctx.Command.Flags)
+
+			if err != nil {
+				return err
+			}
+
+			if ctx.GlobalBool("debug") {
+				printFlagSources(sources)
+			}
+		}
+
+		return before(ctx)
+	}
+}
+
+func commandConfigFile(ctx *cli.Context) *configFile {
+	config, _ := ctx.App.Metadata[configFileMetadataKey].(*configFile)
+	return config
+}
+
+// configuredRepository returns the repository named by the "repo" key of the
+// configuration file, if any
+func configuredRepository(ctx *cli.Context) (string, error) {
+	config := commandConfigFile(ctx)
+
+	if config == nil {
+		return "", nil
+	}
+
+	return config.String(ctx.Command.Name, "repo")
+}
+
+// decode unmarshals the whole configuration file into out, which only needs
+// fields for the keys it is interested in
+func (config *configFile) decode(out interface{}) error {
+	contents, err := ioutil.ReadFile(config.Path)
+
+	if err != nil {
+		return &badArgumentError{argument: "--config", reason: err.Error()}
+	}
+
+	err = yaml.Unmarshal(contents, out)
+
+	if err != nil {
+		return &badArgumentError{argument: "--config", reason: err.Error()}
+	}
+
+	return nil
+}
+
+// flagSource records where the value of a flag came from
+type flagSource struct {
+	Flag   string
+	Source string
+}
+
+// apply sets every flag that was not passed on the command line or through
+// its environment variable to the value in the configuration file, if there
+// is one. Hidden flags hold positional arguments and are left alone.
+//
+// Values are set on the context rather than through altsrc's flag wrappers
+// so that ctx.IsSet reports them as set, just like flags passed on the
+// command line.
+func (config *configFile) apply(ctx *cli.Context, command string, flags []cli.Flag) ([]*flagSource, error) {
+	var sources []*flagSource
+
+	for _, f := range flags {
+		name := flagName(f)
+
+		if isHiddenFlag(f) || !isConfigurableFlag(name) {
+			continue
+		}
+
+		source := &flagSource{Flag: name, Source: "default"}
+		sources = append(sources, source)
+
+		// IsSet is also true for flags set through their environment
+		// variable, so tell the two apart by comparing values
+		if ctx.IsSet(name) {
+			source.Source = "command line"
+
+			if envVar, ok := flagEnvVarMatches(ctx, f); ok {
+				source.Source = fmt.Sprintf("environment (%s)", envVar)
+			}
+
+			continue
+		}
+
+		// A toggle like --no-draft on the command line overrides draft in
+		// the configuration file too
+		if ctx.IsSet(oppositeFlagName(name)) {
+			continue
+		}
+
+		value, err := config.flagValue(command, f)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			continue
+		}
+
+		err = ctx.Set(name, value)
+
+		if err != nil {
+			reason := fmt.Sprintf("invalid value for %s: %v", name, err)
+			return nil, &badArgumentError{argument: "--config", reason: reason}
+		}
+
+		source.Source = fmt.Sprintf("config file (%s)", config.Path)
+	}
+
+	return sources, nil
+}
+
+// flagValue looks up the value for a flag, returning it in the form it would
+// take on the command line. Like altsrc, zero values are treated as not
+// being configured.
+func (config *configFile) flagValue(command string, f cli.Flag) (string, error) {
+	name := flagName(f)
+
+	switch f.(type) {
+	case cli.StringFlag, cli.DurationFlag, cli.GenericFlag:
+		return config.String(command, name)
+	case cli.BoolFlag:
+		value, err := config.Bool(command, name)
+
+		if err != nil || !value {
+			return "", err
+		}
+
+		return strconv.FormatBool(value), nil
+	case cli.IntFlag:
+		value, err := config.Int(command, name)
+
+		if err != nil || value == 0 {
+			return "", err
+		}
+
+		return strconv.Itoa(value), nil
+	}
+
+	return "", nil
+}
+
+// String looks up key in the command's section first, then at the top level
+func (config *configFile) String(command string, key string) (string, error) {
+	if command != "" {
+		value, err := config.Source.String(command + "." + key)
+
+		if err != nil || value != "" {
+			return value, err
+		}
+	}
+
+	return config.Source.String(key)
+}
+
+// Bool looks up key in the command's section first, then at the top level
+func (config *configFile) Bool(command string, key string) (bool, error) {
+	if command != "" {
+		value, err := config.Source.Bool(command + "." + key)
+
+		if err != nil || value {
+			return value, err
+		}
+	}
+
+	return config.Source.Bool(key)
+}
+
+// Int looks up key in the command's section first, then at the top level
+func (config *configFile) Int(command string, key string) (int, error) {
+	if command != "" {
+		value, err := config.Source.Int(command + "." + key)
+
+		if err != nil || value != 0 {
+			return value, err
+		}
+	}
+
+	return config.Source.Int(key)
+}
+
+func printFlagSources(sources []*flagSource) {
+	for _, source := range sources {
+		fmt.Printf("Flag --%s is from %s\n", source.Flag, source.Source)
+	}
+}
+
+// flagName returns the long name of a flag, like "pre-release" for a flag
+// named "pre-release, pre"
+func flagName(f cli.Flag) string {
+	return strings.TrimSpace(strings.Split(f.GetName(), ",")[0])
+}
+
+// oppositeFlagName returns the name of the flag that undoes the given one,
+// like "no-draft" for "draft" and the other way around
+func oppositeFlagName(name string) string {
+	if strings.HasPrefix(name, "no-") {
+		return strings.TrimPrefix(name, "no-")
+	}
+
+	return "no-" + name
+}
+
+// isConfigurableFlag filters out the flags that make no sense to set from
+// the configuration file
+func isConfigurableFlag(name string) bool {
+	switch name {
+	case "", "config", flagName(cli.HelpFlag), flagName(cli.VersionFlag):
+		return false
+	}
+
+	return true
+}
+
+func isHiddenFlag(f cli.Flag) bool {
+	switch typed := f.(type) {
+	case cli.StringFlag:
+		return typed.Hidden
+	case cli.BoolFlag:
+		return typed.Hidden
+	case cli.IntFlag:
+		return typed.Hidden
+	case cli.DurationFlag:
+		return typed.Hidden
+	case cli.GenericFlag:
+		return typed.Hidden
+	}
+
+	return false
+}
+
+// flagEnvVarMatches reports which environment variable the flag's current
+// value came from, if any
+func flagEnvVarMatches(ctx *cli.Context, f cli.Flag) (string, bool) {
+	var envVars string
+
+	switch typed := f.(type) {
+	case cli.StringFlag:
+		envVars = typed.EnvVar
+	case cli.BoolFlag:
+		envVars = typed.EnvVar
+	case cli.IntFlag:
+		envVars = typed.EnvVar
+	case cli.DurationFlag:
+		envVars = typed.EnvVar
+	case cli.GenericFlag:
+		envVars = typed.EnvVar
+	}
+
+	value, ok := ctx.Generic(flagName(f)).(flag.Value)
+
+	if !ok {
+		return "", false
+	}
+
+	for _, envVar := range strings.Split(envVars, ",") {
+		envVar = strings.TrimSpace(envVar)
+
+		if envVar == "" {
+			continue
+		}
+
+		envValue, ok := os.LookupEnv(envVar)
+
+		if !ok {
+			continue
+		}
+
+		// Bool flags accept the same spellings as strconv.ParseBool
+		if parsed, err := strconv.ParseBool(envValue); err == nil {
+			if _, isBool := f.(cli.BoolFlag); isBool {
+				envValue = strconv.FormatBool(parsed)
+			}
+		}
+
+		if envValue == value.String() {
+			return envVar, true
+		}
+	}
+
+	return "", false
+}
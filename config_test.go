@@ -0,0 +1,56 @@
+package main
+
+import (
+	"gopkg.in/urfave/cli.v1/altsrc"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigFileCommandSectionTakesPrecedence(test *testing.T) {
+	dir, err := ioutil.TempDir("", "grease")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, defaultConfigFileName)
+	contents := "assets: dist/*\nupdate-release:\n  assets: build/*\n"
+
+	err = ioutil.WriteFile(path, []byte(contents), 0644)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	source, err := altsrc.NewYamlSourceFromFile(path)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	config := &configFile{Path: path, Source: source}
+
+	value, err := config.String("update-release", "assets")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if value != "build/*" {
+		test.Fatalf("Expected assets to be build/* but got %s", value)
+	}
+
+	value, err = config.String("create-release", "assets")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if value != "dist/*" {
+		test.Fatalf("Expected assets to be dist/* but got %s", value)
+	}
+}
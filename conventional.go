@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionBump is how much of a version changes for a set of commits, from
+// nothing up to the major version
+type versionBump int
+
+const (
+	bumpNone versionBump = iota
+	bumpPatch
+	bumpMinor
+	bumpMajor
+)
+
+// conventionalCommitPattern matches the header of a commit message in the
+// Conventional Commits format, like "feat(assets)!: upload in parallel"
+var conventionalCommitPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// breakingChangeFooterPattern matches the footer that marks a commit as
+// breaking when its header doesn't
+var breakingChangeFooterPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// preReleaseSuffixPattern matches the identifiers --pre-release-suffix
+// accepts, like "rc" or "beta"
+var preReleaseSuffixPattern = regexp.MustCompile(`^[0-9]*[a-zA-Z-][0-9a-zA-Z-]*$`)
+
+type conventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+}
+
+type noReleasableChangesError struct {
+	base string
+}
+
+// parseConventionalCommit parses a commit message, returning false if it
+// doesn't follow the Conventional Commits format
+func parseConventionalCommit(message string) (*conventionalCommit, bool) {
+	header := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	matches := conventionalCommitPattern.FindStringSubmatch(header)
+
+	if matches == nil {
+		return nil, false
+	}
+
+	commit := &conventionalCommit{
+		Type:        strings.ToLower(matches[1]),
+		Scope:       matches[2],
+		Description: matches[4],
+		Breaking:    matches[3] == "!" || breakingChangeFooterPattern.MatchString(message),
+	}
+
+	return commit, true
+}
+
+// bump returns how much the version changes for the commit: breaking changes
+// bump the major version, features the minor version and fixes the patch
+// version. Other types, like docs or chore, don't call for a release.
+func (commit *conventionalCommit) bump() versionBump {
+	switch {
+	case commit.Breaking:
+		return bumpMajor
+	case commit.Type == "feat":
+		return bumpMinor
+	case commit.Type == "fix":
+		return bumpPatch
+	}
+
+	return bumpNone
+}
+
+func (bump versionBump) String() string {
+	switch bump {
+	case bumpMajor:
+		return "major"
+	case bumpMinor:
+		return "minor"
+	case bumpPatch:
+		return "patch"
+	}
+
+	return "none"
+}
+
+// nextVersion bumps latest, keeping its prefix. With a pre-release suffix
+// like "rc" the result is the next pre-release of the bumped version, one
+// past the highest of existing, like v1.3.0-rc.2 after v1.3.0-rc.1.
+func nextVersion(latest *semanticVersion, bump versionBump, suffix string, existing []*semanticVersion) *semanticVersion {
+	next := &semanticVersion{
+		Prefix: latest.Prefix,
+		Major:  latest.Major,
+		Minor:  latest.Minor,
+		Patch:  latest.Patch,
+	}
+
+	switch bump {
+	case bumpMajor:
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	case bumpMinor:
+		next.Minor++
+		next.Patch = 0
+	case bumpPatch:
+		next.Patch++
+	}
+
+	if suffix == "" {
+		return next
+	}
+
+	number := 0
+
+	for _, version := range existing {
+		if version.Major != next.Major || version.Minor != next.Minor || version.Patch != next.Patch {
+			continue
+		}
+
+		if len(version.PreRelease) != 2 || version.PreRelease[0] != suffix {
+			continue
+		}
+
+		if n, err := strconv.Atoi(version.PreRelease[1]); err == nil && n > number {
+			number = n
+		}
+	}
+
+	next.PreRelease = []string{suffix, strconv.Itoa(number + 1)}
+
+	return next
+}
+
+// latestReleaseVersion returns the highest version among the tags that
+// isn't a pre-release, along with every tag that is a semantic version.
+// Tags that aren't semantic versions are ignored.
+func latestReleaseVersion(tags []string) (*semanticVersion, []*semanticVersion) {
+	var latest *semanticVersion
+	var versions []*semanticVersion
+
+	for _, tag := range tags {
+		version, err := parseSemanticVersion(tag)
+
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+
+		if !version.IsPreRelease() && (latest == nil || version.Compare(latest) > 0) {
+			latest = version
+		}
+	}
+
+	return latest, versions
+}
+
+// calculateNextVersion works out the next version from the Conventional
+// Commits between the latest release tag and head, which defaults to the
+// repository's default branch
+func calculateNextVersion(netCtx context.Context, repo *gitHubRepo, head string, suffix string, gitHubToken string, debug bool) (string, error) {
+	if suffix != "" && !preReleaseSuffixPattern.MatchString(suffix) {
+		reason := fmt.Sprintf("expected an identifier like rc or beta but found \"%s\"", suffix)
+		return "", &badArgumentError{argument: "--pre-release-suffix", reason: reason}
+	}
+
+	if head == "" {
+		defaultBranch, err := repo.DefaultBranch(netCtx, gitHubToken)
+
+		if err != nil {
+			return "", err
+		}
+
+		head = defaultBranch
+	}
+
+	tags, err := repo.ListTags(netCtx, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	latest, versions := latestReleaseVersion(tags)
+
+	if latest == nil {
+		return "", &badArgumentError{argument: "REPO", reason: "no tags like v1.2.3 were found to start from; tag the first release by hand"}
+	}
+
+	if debug {
+		fmt.Printf("Latest release tag is: %s\n", latest)
+	}
+
+	comparison, err := repo.CompareCommits(netCtx, latest.String(), head, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	if comparison.TotalCommits > len(comparison.Commits) {
+		fmt.Fprintf(os.Stderr, "Warning: GitHub only returned %d of the %d commits since %s; the version may be bumped too little\n",
+			len(comparison.Commits), comparison.TotalCommits, latest)
+	}
+
+	bump := bumpNone
+
+	for _, commit := range comparison.Commits {
+		parsed, ok := parseConventionalCommit(stringValue(commit.Message))
+		commitBump := bumpNone
+
+		if ok {
+			commitBump = parsed.bump()
+		}
+
+		if debug {
+			subject := strings.TrimSpace(strings.SplitN(stringValue(commit.Message), "\n", 2)[0])
+			fmt.Printf("Commit %s (%s bump): %s\n", shortSHA(stringValue(commit.SHA)), commitBump, subject)
+		}
+
+		if commitBump > bump {
+			bump = commitBump
+		}
+	}
+
+	if bump == bumpNone {
+		return "", &noReleasableChangesError{base: latest.String()}
+	}
+
+	return nextVersion(latest, bump, suffix, versions).String(), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+
+	return sha
+}
+
+func (e *noReleasableChangesError) Error() string {
+	message := fmt.Sprintf("There are no feat, fix or breaking changes since %s, so there is nothing to release", e.base)
+	return message
+}
+
+// ExitCode is 1 rather than one of the usage error codes since nothing is
+// wrong with how grease was called
+func (e *noReleasableChangesError) ExitCode() int {
+	return 1
+}
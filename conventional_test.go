@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseConventionalCommit(test *testing.T) {
+	expectations := map[string]versionBump{
+		"feat(assets): upload in parallel":                 bumpMinor,
+		"fix: retry failed uploads\n\nCloses #12":          bumpPatch,
+		"feat!: drop the list-files command":               bumpMajor,
+		"refactor: rename flags\n\nBREAKING CHANGE: --pre": bumpMajor,
+		"docs: explain --notes-file":                       bumpNone,
+		"Merge pull request #3 from timberio/fix":          bumpNone,
+	}
+
+	for message, expected := range expectations {
+		bump := bumpNone
+
+		if commit, ok := parseConventionalCommit(message); ok {
+			bump = commit.bump()
+		}
+
+		if bump != expected {
+			test.Fatalf("Expected %q to be a %s bump but got %s", message, expected, bump)
+		}
+	}
+}
+
+func TestNextVersion(test *testing.T) {
+	latest, versions := latestReleaseVersion([]string{"v1.2.0", "v1.3.0-rc.1", "v1.2.1-rc.1", "v0.9.0", "nightly"})
+
+	if latest.String() != "v1.2.0" {
+		test.Fatalf("Expected the latest release to be v1.2.0 but got %s", latest)
+	}
+
+	expectations := []struct {
+		bump     versionBump
+		suffix   string
+		expected string
+	}{
+		{bumpPatch, "", "v1.2.1"},
+		{bumpMinor, "", "v1.3.0"},
+		{bumpMajor, "", "v2.0.0"},
+		{bumpMinor, "rc", "v1.3.0-rc.2"},
+		{bumpMajor, "rc", "v2.0.0-rc.1"},
+	}
+
+	for _, expectation := range expectations {
+		next := nextVersion(latest, expectation.bump, expectation.suffix, versions).String()
+
+		if next != expectation.expected {
+			test.Fatalf("Expected a %s bump with suffix %q to give %s but got %s", expectation.bump, expectation.suffix, expectation.expected, next)
+		}
+	}
+}
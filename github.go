@@ -1,10 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"github.com/google/go-github/github"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"sync"
+	"time"
 )
 
 /*
@@ -20,6 +26,16 @@ var userAgent = fmt.Sprintf("%s %s", userAgentBase, version)
 type gitHubRepo struct {
 	Owner string
 	Name  string
+	Retry *retryPolicy
+
+	// BaseURL and UploadURL point the client at a GitHub Enterprise server;
+	// when nil the public GitHub endpoints are used
+	BaseURL   *url.URL
+	UploadURL *url.URL
+
+	clientLock  sync.Mutex
+	client      *github.Client
+	clientToken string
 }
 
 type gitHubRelease struct {
@@ -29,18 +45,145 @@ type gitHubRelease struct {
 	Body            *string `json:"body,omitempty"`
 	Draft           *bool   `json:"draft"`
 	PreRelease      *bool   `json:"prerelease"`
+
+	// The fields below are only filled in for releases read from GitHub and
+	// are never sent back
+	ID          *int           `json:"id,omitempty"`
+	HTMLURL     *string        `json:"html_url,omitempty"`
+	Author      *string        `json:"author,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at,omitempty"`
+	PublishedAt *time.Time     `json:"published_at,omitempty"`
+	Assets      []*gitHubAsset `json:"assets,omitempty"`
+}
+
+type gitHubAsset struct {
+	ID            *int    `json:"id"`
+	Name          *string `json:"name"`
+	Size          *int    `json:"size"`
+	State         *string `json:"state"`
+	ContentType   *string `json:"content_type,omitempty"`
+	DownloadCount *int    `json:"download_count,omitempty"`
+	URL           *string `json:"url,omitempty"`
+	DownloadURL   *string `json:"browser_download_url,omitempty"`
+}
+
+type gitHubCommit struct {
+	SHA         *string    `json:"sha"`
+	Message     *string    `json:"message"`
+	AuthorName  *string    `json:"author_name"`
+	AuthorLogin *string    `json:"author_login,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	HTMLURL     *string    `json:"html_url,omitempty"`
+}
+
+type gitHubComparison struct {
+	// BaseCommit is the common ancestor of the two sides of the comparison
+	BaseCommit *gitHubCommit
+	// Commits holds the commits reachable from head but not from base,
+	// oldest first. GitHub returns at most 250 of them, so TotalCommits may
+	// be larger than the number of commits.
+	Commits      []*gitHubCommit
+	TotalCommits int
+}
+
+// gitHubRef is a Git reference, like refs/tags/v1.0.0. ObjectSHA is the SHA of
+// what it points at, which is a commit or, for annotated tags, a tag object.
+type gitHubRef struct {
+	Ref        *string `json:"ref"`
+	ObjectSHA  *string `json:"object_sha"`
+	ObjectType *string `json:"object_type"`
+}
+
+// gitHubTagger is who an annotated tag is attributed to
+type gitHubTagger struct {
+	Name  *string    `json:"name"`
+	Email *string    `json:"email"`
+	Date  *time.Time `json:"date"`
+}
+
+type gitHubPullRequest struct {
+	Number    *int     `json:"number"`
+	Title     *string  `json:"title"`
+	HTMLURL   *string  `json:"html_url"`
+	Author    *string  `json:"author"`
+	AuthorURL *string  `json:"author_url"`
+	Labels    []string `json:"labels"`
+}
+
+// FindReleaseByTag looks up the release for the given tag, including draft
+// releases. A missing release is not an error: both the id and the release
+// are nil in that case.
+func (repo *gitHubRepo) FindReleaseByTag(ctx context.Context, tag string, token string) (*int, *gitHubRelease, error) {
+	var release *github.RepositoryRelease
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		release, _, err = client.Repositories.GetReleaseByTag(ctx, repo.Owner, repo.Name, tag)
+		return err
+	})
+
+	if err == nil {
+		return release.ID, newGitHubRelease(release), nil
+	}
+
+	if !isNotFoundError(err) {
+		return nil, nil, err
+	}
+
+	// GitHub never returns drafts when looking releases up by tag, so look
+	// for a draft among all the releases before giving up
+	releases, err := repo.ListReleases(ctx, token)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, release := range releases {
+		if stringValue(release.TagName) == tag {
+			return release.ID, release, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// FindLatestRelease looks up the latest release, which is the most recently
+// published release that is neither a draft nor a pre-release. It returns
+// nil if there is none.
+func (repo *gitHubRepo) FindLatestRelease(ctx context.Context, token string) (*gitHubRelease, error) {
+	var release *github.RepositoryRelease
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		release, _, err = client.Repositories.GetLatestRelease(ctx, repo.Owner, repo.Name)
+		return err
+	})
+
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newGitHubRelease(release), nil
 }
 
-func (repo *gitHubRepo) GetReleaseIdByTag(ctx context.Context, tag string, token string) (*int, error) {
+func (repo *gitHubRepo) GetRelease(ctx context.Context, releaseId int, token string) (*gitHubRelease, error) {
+	var release *github.RepositoryRelease
 
-	client := newGitHubAPIClient(ctx, token)
-	release, _, err := client.Repositories.GetReleaseByTag(ctx, repo.Owner, repo.Name, tag)
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		release, _, err = client.Repositories.GetRelease(ctx, repo.Owner, repo.Name, releaseId)
+		return err
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return release.ID, err
+	return newGitHubRelease(release), nil
 }
 
 func (repo *gitHubRepo) CreateRelease(ctx context.Context, release *gitHubRelease, token string) (*int, error) {
@@ -53,8 +196,13 @@ func (repo *gitHubRepo) CreateRelease(ctx context.Context, release *gitHubReleas
 		Prerelease:      release.PreRelease,
 	}
 
-	client := newGitHubAPIClient(ctx, token)
-	createdRelease, _, err := client.Repositories.CreateRelease(ctx, repo.Owner, repo.Name, gRelease)
+	var createdRelease *github.RepositoryRelease
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, false, func(attempt int) (err error) {
+		createdRelease, _, err = client.Repositories.CreateRelease(ctx, repo.Owner, repo.Name, gRelease)
+		return err
+	})
 
 	if err != nil {
 		return nil, err
@@ -71,8 +219,13 @@ func (repo *gitHubRepo) UpdateRelease(ctx context.Context, releaseId int, releas
 		Prerelease: release.PreRelease,
 	}
 
-	client := newGitHubAPIClient(ctx, token)
-	updatedRelease, _, err := client.Repositories.EditRelease(ctx, repo.Owner, repo.Name, releaseId, gRelease)
+	var updatedRelease *github.RepositoryRelease
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		updatedRelease, _, err = client.Repositories.EditRelease(ctx, repo.Owner, repo.Name, releaseId, gRelease)
+		return err
+	})
 
 	if err != nil {
 		return nil, err
@@ -86,12 +239,600 @@ func (repo *gitHubRepo) UploadReleaseAsset(ctx context.Context, releaseId int, f
 		Name: filename,
 	}
 
-	client := newGitHubAPIClient(ctx, token)
-	_, _, err := client.Repositories.UploadReleaseAsset(ctx, repo.Owner, repo.Name, releaseId, opts, file)
+	info, err := file.Stat()
+
+	if err != nil {
+		return err
+	}
+
+	client := repo.apiClient(ctx, token)
+	err = repo.Retry.do(ctx, true, func(attempt int) error {
+		if attempt > 0 {
+			uploaded, err := repo.checkPreviousUpload(ctx, releaseId, filename, info.Size(), token)
+
+			if err != nil || uploaded {
+				return err
+			}
+
+			_, err = file.Seek(0, io.SeekStart)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		_, _, err := client.Repositories.UploadReleaseAsset(ctx, repo.Owner, repo.Name, releaseId, opts, file)
+		return err
+	})
+
+	return err
+}
+
+// checkPreviousUpload looks at what a failed upload attempt left behind
+// before retrying it. It reports whether the asset was uploaded after all,
+// which happens when only the response was lost. A partial asset is deleted,
+// since it would make GitHub reject the retry as a duplicate.
+func (repo *gitHubRepo) checkPreviousUpload(ctx context.Context, releaseId int, filename string, size int64, token string) (bool, error) {
+	assets, err := repo.ListReleaseAssets(ctx, releaseId, token)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, asset := range assets {
+		if stringValue(asset.Name) != filename {
+			continue
+		}
+
+		if stringValue(asset.State) == string(assetUploaded) {
+			return asset.Size != nil && int64(*asset.Size) == size, nil
+		}
+
+		return false, repo.DeleteReleaseAsset(ctx, *asset.ID, token)
+	}
+
+	return false, nil
+}
+
+func (repo *gitHubRepo) ListReleaseAssets(ctx context.Context, releaseId int, token string) ([]*gitHubAsset, error) {
+	client := repo.apiClient(ctx, token)
+	opts := &github.ListOptions{PerPage: 100}
+
+	var assets []*gitHubAsset
+
+	for {
+		var page []*github.ReleaseAsset
+		var response *github.Response
+
+		err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+			page, response, err = client.Repositories.ListReleaseAssets(ctx, repo.Owner, repo.Name, releaseId, opts)
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range page {
+			assets = append(assets, newGitHubAsset(asset))
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+
+		opts.Page = response.NextPage
+	}
+
+	return assets, nil
+}
+
+func (repo *gitHubRepo) DeleteReleaseAsset(ctx context.Context, assetId int, token string) error {
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) error {
+		_, err := client.Repositories.DeleteReleaseAsset(ctx, repo.Owner, repo.Name, assetId)
+		return err
+	})
+
+	return err
+}
+
+func (repo *gitHubRepo) DeleteRelease(ctx context.Context, releaseId int, token string) error {
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		_, err = client.Repositories.DeleteRelease(ctx, repo.Owner, repo.Name, releaseId)
+		return err
+	})
+
+	return err
+}
+
+// FindTagRef looks up the ref for a tag through the Git refs API. A missing
+// tag is not an error: the ref is nil in that case.
+func (repo *gitHubRepo) FindTagRef(ctx context.Context, tag string, token string) (*gitHubRef, error) {
+	var refs []*github.Reference
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		refs, _, err = client.Git.GetRefs(ctx, repo.Owner, repo.Name, "tags/"+tag)
+		return err
+	})
+
+	if isNotFoundError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Without an exact match GitHub returns every tag that starts with the
+	// name instead
+	for _, ref := range refs {
+		if ref.GetRef() == "refs/tags/"+tag {
+			return newGitHubRef(ref), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CreateTagRef creates a tag pointing at sha, which is either a commit for a
+// lightweight tag or a tag object for an annotated one
+func (repo *gitHubRepo) CreateTagRef(ctx context.Context, tag string, sha string, token string) error {
+	ref := &github.Reference{
+		Ref:    github.String("refs/tags/" + tag),
+		Object: &github.GitObject{SHA: &sha},
+	}
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, false, func(attempt int) (err error) {
+		_, _, err = client.Git.CreateRef(ctx, repo.Owner, repo.Name, ref)
+		return err
+	})
+
+	return err
+}
+
+// CreateTagObject creates an annotated tag object for the commit sha and
+// returns the SHA of the tag object. The tag only shows up in the repository
+// once a ref pointing at the tag object is created with CreateTagRef. When
+// tagger is nil, GitHub attributes the tag to the owner of the token.
+func (repo *gitHubRepo) CreateTagObject(ctx context.Context, tag string, message string, sha string, tagger *gitHubTagger, token string) (string, error) {
+	gTag := &github.Tag{
+		Tag:     &tag,
+		Message: &message,
+		Object:  &github.GitObject{SHA: &sha, Type: github.String("commit")},
+	}
+
+	if tagger != nil {
+		gTag.Tagger = &github.CommitAuthor{
+			Name:  tagger.Name,
+			Email: tagger.Email,
+			Date:  tagger.Date,
+		}
+	}
+
+	var created *github.Tag
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, false, func(attempt int) (err error) {
+		created, _, err = client.Git.CreateTag(ctx, repo.Owner, repo.Name, gTag)
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return created.GetSHA(), nil
+}
+
+// UpdateTagRef moves a tag to the commit sha. Without force, the tag can only
+// be moved forward to a descendant of the commit it points at.
+func (repo *gitHubRepo) UpdateTagRef(ctx context.Context, tag string, sha string, force bool, token string) error {
+	ref := &github.Reference{
+		Ref:    github.String("refs/tags/" + tag),
+		Object: &github.GitObject{SHA: &sha},
+	}
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		_, _, err = client.Git.UpdateRef(ctx, repo.Owner, repo.Name, ref, force)
+		return err
+	})
 
 	return err
 }
 
+func (repo *gitHubRepo) DeleteTagRef(ctx context.Context, tag string, token string) error {
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		_, err = client.Git.DeleteRef(ctx, repo.Owner, repo.Name, "tags/"+tag)
+		return err
+	})
+
+	return err
+}
+
+func (repo *gitHubRepo) RenameReleaseAsset(ctx context.Context, assetId int, name string, token string) error {
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		_, _, err = client.Repositories.EditReleaseAsset(ctx, repo.Owner, repo.Name, assetId, &github.ReleaseAsset{Name: &name})
+		return err
+	})
+
+	return err
+}
+
+// ListReleases returns every release of the repository, newest first
+func (repo *gitHubRepo) ListReleases(ctx context.Context, token string) ([]*gitHubRelease, error) {
+	client := repo.apiClient(ctx, token)
+	opts := &github.ListOptions{PerPage: 100}
+
+	var releases []*gitHubRelease
+
+	for {
+		var page []*github.RepositoryRelease
+		var response *github.Response
+
+		err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+			page, response, err = client.Repositories.ListReleases(ctx, repo.Owner, repo.Name, opts)
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, release := range page {
+			releases = append(releases, newGitHubRelease(release))
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+
+		opts.Page = response.NextPage
+	}
+
+	return releases, nil
+}
+
+func (repo *gitHubRepo) CompareCommits(ctx context.Context, base string, head string, token string) (*gitHubComparison, error) {
+	var comparison *github.CommitsComparison
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		comparison, _, err = client.Repositories.CompareCommits(ctx, repo.Owner, repo.Name, base, head)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &gitHubComparison{
+		Commits:      make([]*gitHubCommit, len(comparison.Commits)),
+		TotalCommits: comparison.GetTotalCommits(),
+	}
+
+	if comparison.MergeBaseCommit != nil {
+		converted.BaseCommit = newGitHubCommit(comparison.MergeBaseCommit)
+	}
+
+	for i := range comparison.Commits {
+		converted.Commits[i] = newGitHubCommit(&comparison.Commits[i])
+	}
+
+	return converted, nil
+}
+
+// SearchMergedPullRequests finds the pull requests of the repository that
+// were merged between since and until, inclusive
+func (repo *gitHubRepo) SearchMergedPullRequests(ctx context.Context, since time.Time, until time.Time, token string) ([]*gitHubPullRequest, error) {
+	const searchTimeFormat = "2006-01-02T15:04:05-07:00"
+
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged merged:%s..%s",
+		repo.Owner, repo.Name, since.UTC().Format(searchTimeFormat), until.UTC().Format(searchTimeFormat))
+
+	client := repo.apiClient(ctx, token)
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	var pullRequests []*gitHubPullRequest
+
+	for {
+		var result *github.IssuesSearchResult
+		var response *github.Response
+
+		err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+			result, response, err = client.Search.Issues(ctx, query, opts)
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range result.Issues {
+			pullRequests = append(pullRequests, newGitHubPullRequest(&result.Issues[i]))
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+
+		opts.Page = response.NextPage
+	}
+
+	return pullRequests, nil
+}
+
+// GetPullRequestMergeCommitSHA returns the SHA of the commit a pull request
+// was merged as
+func (repo *gitHubRepo) GetPullRequestMergeCommitSHA(ctx context.Context, number int, token string) (string, error) {
+	var pullRequest *github.PullRequest
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		pullRequest, _, err = client.PullRequests.Get(ctx, repo.Owner, repo.Name, number)
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return pullRequest.GetMergeCommitSHA(), nil
+}
+
+// ListTags returns the names of every tag of the repository
+func (repo *gitHubRepo) ListTags(ctx context.Context, token string) ([]string, error) {
+	client := repo.apiClient(ctx, token)
+	opts := &github.ListOptions{PerPage: 100}
+
+	var tags []string
+
+	for {
+		var page []*github.RepositoryTag
+		var response *github.Response
+
+		err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+			page, response, err = client.Repositories.ListTags(ctx, repo.Owner, repo.Name, opts)
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range page {
+			tags = append(tags, tag.GetName())
+		}
+
+		if response.NextPage == 0 {
+			break
+		}
+
+		opts.Page = response.NextPage
+	}
+
+	return tags, nil
+}
+
+// GetCommitSHA resolves a commit-ish, like a branch or tag name, to the SHA
+// of the commit it points at
+func (repo *gitHubRepo) GetCommitSHA(ctx context.Context, ref string, token string) (string, error) {
+	var sha string
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		sha, _, err = client.Repositories.GetCommitSHA1(ctx, repo.Owner, repo.Name, ref, "")
+		return err
+	})
+
+	return sha, err
+}
+
+// GetCommit looks up a commit by SHA or any other commit-ish
+func (repo *gitHubRepo) GetCommit(ctx context.Context, ref string, token string) (*gitHubCommit, error) {
+	var commit *github.RepositoryCommit
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		commit, _, err = client.Repositories.GetCommit(ctx, repo.Owner, repo.Name, ref)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newGitHubCommit(commit), nil
+}
+
+// DefaultBranch returns the name of the repository's default branch
+func (repo *gitHubRepo) DefaultBranch(ctx context.Context, token string) (string, error) {
+	var repository *github.Repository
+
+	client := repo.apiClient(ctx, token)
+	err := repo.Retry.do(ctx, true, func(attempt int) (err error) {
+		repository, _, err = client.Repositories.Get(ctx, repo.Owner, repo.Name)
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return repository.GetDefaultBranch(), nil
+}
+
+// releaseChanges compares the desired state of a release with its current
+// state and returns a release holding only the fields that differ. Fields
+// that are nil on desired are left alone. It returns nil when nothing needs
+// to change.
+func releaseChanges(current *gitHubRelease, desired *gitHubRelease) *gitHubRelease {
+	changes := &gitHubRelease{TagName: desired.TagName}
+	changed := false
+
+	if desired.Name != nil && (current.Name == nil || *current.Name != *desired.Name) {
+		changes.Name = desired.Name
+		changed = true
+	}
+
+	if desired.Body != nil && (current.Body == nil || *current.Body != *desired.Body) {
+		changes.Body = desired.Body
+		changed = true
+	}
+
+	if desired.Draft != nil && (current.Draft == nil || *current.Draft != *desired.Draft) {
+		changes.Draft = desired.Draft
+		changed = true
+	}
+
+	if desired.PreRelease != nil && (current.PreRelease == nil || *current.PreRelease != *desired.PreRelease) {
+		changes.PreRelease = desired.PreRelease
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return changes
+}
+
+func newGitHubRelease(release *github.RepositoryRelease) *gitHubRelease {
+	converted := &gitHubRelease{
+		TagName:         release.TagName,
+		TargetCommitish: release.TargetCommitish,
+		Name:            release.Name,
+		Body:            release.Body,
+		Draft:           release.Draft,
+		PreRelease:      release.Prerelease,
+		ID:              release.ID,
+		HTMLURL:         release.HTMLURL,
+	}
+
+	if release.Author != nil {
+		converted.Author = release.Author.Login
+	}
+
+	if release.CreatedAt != nil {
+		converted.CreatedAt = &release.CreatedAt.Time
+	}
+
+	if release.PublishedAt != nil {
+		converted.PublishedAt = &release.PublishedAt.Time
+	}
+
+	for i := range release.Assets {
+		converted.Assets = append(converted.Assets, newGitHubAsset(&release.Assets[i]))
+	}
+
+	return converted
+}
+
+func isNotFoundError(err error) bool {
+	errorResponse, ok := err.(*github.ErrorResponse)
+
+	return ok && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusNotFound
+}
+
+func newGitHubCommit(commit *github.RepositoryCommit) *gitHubCommit {
+	converted := &gitHubCommit{
+		SHA:     commit.SHA,
+		HTMLURL: commit.HTMLURL,
+	}
+
+	if commit.Commit != nil {
+		converted.Message = commit.Commit.Message
+
+		if commit.Commit.Author != nil {
+			converted.AuthorName = commit.Commit.Author.Name
+		}
+
+		if commit.Commit.Committer != nil {
+			converted.Date = commit.Commit.Committer.Date
+		}
+	}
+
+	if commit.Author != nil {
+		converted.AuthorLogin = commit.Author.Login
+	}
+
+	return converted
+}
+
+func newGitHubPullRequest(issue *github.Issue) *gitHubPullRequest {
+	pullRequest := &gitHubPullRequest{
+		Number:  issue.Number,
+		Title:   issue.Title,
+		HTMLURL: issue.HTMLURL,
+	}
+
+	if issue.User != nil {
+		pullRequest.Author = issue.User.Login
+		pullRequest.AuthorURL = issue.User.HTMLURL
+	}
+
+	for _, label := range issue.Labels {
+		pullRequest.Labels = append(pullRequest.Labels, label.GetName())
+	}
+
+	return pullRequest
+}
+
+func newGitHubRef(ref *github.Reference) *gitHubRef {
+	converted := &gitHubRef{
+		Ref: ref.Ref,
+	}
+
+	if ref.Object != nil {
+		converted.ObjectSHA = ref.Object.SHA
+		converted.ObjectType = ref.Object.Type
+	}
+
+	return converted
+}
+
+func newGitHubAsset(asset *github.ReleaseAsset) *gitHubAsset {
+	return &gitHubAsset{
+		ID:            asset.ID,
+		Name:          asset.Name,
+		Size:          asset.Size,
+		State:         asset.State,
+		ContentType:   asset.ContentType,
+		DownloadCount: asset.DownloadCount,
+		URL:           asset.URL,
+		DownloadURL:   asset.BrowserDownloadURL,
+	}
+}
+
+// apiClient returns the client used to talk to the GitHub API, creating it
+// on first use. The client is shared between calls so that concurrent
+// uploads reuse connections and see the same rate limit state.
+func (repo *gitHubRepo) apiClient(ctx context.Context, token string) *github.Client {
+	repo.clientLock.Lock()
+	defer repo.clientLock.Unlock()
+
+	if repo.client == nil || repo.clientToken != token {
+		repo.client = newGitHubAPIClient(ctx, token)
+		repo.clientToken = token
+
+		if repo.BaseURL != nil {
+			repo.client.BaseURL = repo.BaseURL
+		}
+
+		if repo.UploadURL != nil {
+			repo.client.UploadURL = repo.UploadURL
+		}
+	}
+
+	return repo.client
+}
+
 func newGitHubAPIClient(ctx context.Context, token string) *github.Client {
 	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	tokenClient := oauth2.NewClient(ctx, tokenSource)
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReleaseChanges(test *testing.T) {
+	tagName := "v1.0.0"
+	currentName := "v1.0.0"
+	currentBody := "Old notes"
+	currentDraft := true
+	currentPreRelease := false
+
+	current := &gitHubRelease{
+		TagName:    &tagName,
+		Name:       &currentName,
+		Body:       &currentBody,
+		Draft:      &currentDraft,
+		PreRelease: &currentPreRelease,
+	}
+
+	desiredBody := "New notes"
+	desiredDraft := true
+
+	desired := &gitHubRelease{
+		TagName: &tagName,
+		Body:    &desiredBody,
+		Draft:   &desiredDraft,
+	}
+
+	changes := releaseChanges(current, desired)
+
+	if changes == nil {
+		test.Fatalf("Expected changes but got none")
+	}
+
+	if changes.Body == nil || *changes.Body != desiredBody {
+		test.Fatalf("Expected body to change to %s", desiredBody)
+	}
+
+	if changes.Name != nil {
+		test.Fatalf("Did not expect name to change but got %s", *changes.Name)
+	}
+
+	if changes.Draft != nil {
+		test.Fatalf("Did not expect draft to change since it already matches")
+	}
+
+	if changes.PreRelease != nil {
+		test.Fatalf("Did not expect pre-release to change since it was not passed")
+	}
+}
+
+func TestReleaseChangesUpToDate(test *testing.T) {
+	tagName := "v1.0.0"
+	name := "v1.0.0"
+
+	current := &gitHubRelease{TagName: &tagName, Name: &name}
+	desired := &gitHubRelease{TagName: &tagName, Name: &name}
+
+	changes := releaseChanges(current, desired)
+
+	if changes != nil {
+		test.Fatalf("Expected no changes but got %+v", changes)
+	}
+}
+
+func TestFindReleaseByTagFindsDrafts(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/repos/timberio/grease/releases/tags/v1.1.0":
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message": "Not Found"}`))
+		case "/repos/timberio/grease/releases":
+			writer.Write([]byte(`[
+				{"id": 2, "tag_name": "v1.1.0", "draft": true},
+				{"id": 1, "tag_name": "v1.0.0"}
+			]`))
+		default:
+			test.Fatalf("Did not expect a request for %s", request.URL.Path)
+		}
+	}))
+	defer server.Close()
+	defer redirectGitHub(server)()
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease"}
+
+	releaseId, release, err := repo.FindReleaseByTag(context.Background(), "v1.1.0", "token")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if releaseId == nil || *releaseId != 2 || release.Draft == nil || !*release.Draft {
+		test.Fatalf("Expected to find the draft release but got %v", release)
+	}
+}
+
+func TestUploadReleaseAssetResponseLost(test *testing.T) {
+	uploads := 0
+	assets := "[]"
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method + " " + request.URL.Path {
+		case "POST /repos/timberio/grease/releases/1/assets":
+			uploads++
+
+			if uploads > 1 {
+				test.Fatalf("Did not expect the asset to be uploaded again")
+			}
+
+			// GitHub stores the asset but the response never arrives
+			contents, _ := ioutil.ReadAll(request.Body)
+			assets = fmt.Sprintf(`[{"id": 5, "name": "a.txt", "size": %d, "state": "uploaded"}]`, len(contents))
+			writer.Header().Set("Retry-After", "0")
+			writer.WriteHeader(http.StatusBadGateway)
+		case "GET /repos/timberio/grease/releases/1/assets":
+			writer.Write([]byte(assets))
+		default:
+			test.Fatalf("Did not expect a %s request for %s", request.Method, request.URL.Path)
+		}
+	}))
+	defer server.Close()
+	defer redirectGitHub(server)()
+
+	file, err := ioutil.TempFile("", "grease")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	file.WriteString("contents")
+	file.Seek(0, 0)
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", Retry: &retryPolicy{MaxRetries: 1, Timeout: time.Minute}}
+
+	err = repo.UploadReleaseAsset(context.Background(), 1, file, "a.txt", "token")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+}
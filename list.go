@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"regexp"
+	"text/tabwriter"
+	"time"
+)
+
+// releaseFilter picks the releases list-releases prints. Zero values don't
+// filter anything out.
+type releaseFilter struct {
+	DraftsOnly      bool
+	PreReleasesOnly bool
+	TagPattern      *regexp.Regexp
+	CreatedBefore   time.Time
+	CreatedAfter    time.Time
+	Limit           int
+}
+
+func newReleaseFilter(ctx *cli.Context) (*releaseFilter, error) {
+	filter := &releaseFilter{
+		DraftsOnly:      ctx.Bool("drafts"),
+		PreReleasesOnly: ctx.Bool("pre-releases"),
+		Limit:           ctx.Int("limit"),
+	}
+
+	if pattern := ctx.String("tag-pattern"); pattern != "" {
+		compiled, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, &badArgumentError{argument: "--tag-pattern", reason: err.Error()}
+		}
+
+		filter.TagPattern = compiled
+	}
+
+	var err error
+
+	filter.CreatedBefore, err = parseTimeFlag(ctx, "created-before")
+
+	if err != nil {
+		return nil, err
+	}
+
+	filter.CreatedAfter, err = parseTimeFlag(ctx, "created-after")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit < 0 {
+		reason := fmt.Sprintf("expected at least 0 but found %d", filter.Limit)
+		return nil, &badArgumentError{argument: "--limit", reason: reason}
+	}
+
+	return filter, nil
+}
+
+// parseTimeFlag parses a flag holding either a date like 2017-08-23, which
+// means midnight UTC, or a time in RFC 3339 format. An empty value gives the
+// zero time.
+func parseTimeFlag(ctx *cli.Context, name string) (time.Time, error) {
+	value := ctx.String(name)
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	reason := fmt.Sprintf("expected a date like 2017-08-23 or a time like 2017-08-23T15:04:05Z but found \"%s\"", value)
+	return time.Time{}, &badArgumentError{argument: "--" + name, reason: reason}
+}
+
+// filterReleases returns the releases that match every filter, keeping
+// their order
+func filterReleases(releases []*gitHubRelease, filter *releaseFilter) []*gitHubRelease {
+	var matched []*gitHubRelease
+
+	for _, release := range releases {
+		if filter.Limit > 0 && len(matched) >= filter.Limit {
+			break
+		}
+
+		if filter.DraftsOnly && !boolValue(release.Draft) {
+			continue
+		}
+
+		if filter.PreReleasesOnly && !boolValue(release.PreRelease) {
+			continue
+		}
+
+		if filter.TagPattern != nil && !filter.TagPattern.MatchString(stringValue(release.TagName)) {
+			continue
+		}
+
+		if !filter.CreatedBefore.IsZero() && (release.CreatedAt == nil || !release.CreatedAt.Before(filter.CreatedBefore)) {
+			continue
+		}
+
+		if !filter.CreatedAfter.IsZero() && (release.CreatedAt == nil || !release.CreatedAt.After(filter.CreatedAfter)) {
+			continue
+		}
+
+		matched = append(matched, release)
+	}
+
+	return matched
+}
+
+// writeReleaseTable prints one line per release for people to read
+func writeReleaseTable(out io.Writer, summaries []*releaseSummary) {
+	writer := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "TAG\tNAME\tDRAFT\tPRE-RELEASE\tCREATED\tASSETS")
+
+	for _, summary := range summaries {
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%t\t%s\t%d\n", summary.TagName, summary.Name, summary.Draft, summary.PreRelease, formatTime(summary.CreatedAt), len(summary.Assets))
+	}
+
+	writer.Flush()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestFilterReleases(test *testing.T) {
+	newRelease := func(tag string, draft bool, preRelease bool, created string) *gitHubRelease {
+		createdAt, _ := time.Parse("2006-01-02", created)
+		return &gitHubRelease{TagName: &tag, Draft: &draft, PreRelease: &preRelease, CreatedAt: &createdAt}
+	}
+
+	releases := []*gitHubRelease{
+		newRelease("v1.2.0-rc.1", false, true, "2017-09-01"),
+		newRelease("v1.1.0", true, false, "2017-08-20"),
+		newRelease("v1.0.0", false, false, "2017-08-01"),
+		newRelease("nightly", false, true, "2017-07-01"),
+	}
+
+	expectations := []struct {
+		filter   *releaseFilter
+		expected []string
+	}{
+		{&releaseFilter{}, []string{"v1.2.0-rc.1", "v1.1.0", "v1.0.0", "nightly"}},
+		{&releaseFilter{DraftsOnly: true}, []string{"v1.1.0"}},
+		{&releaseFilter{PreReleasesOnly: true}, []string{"v1.2.0-rc.1", "nightly"}},
+		{&releaseFilter{TagPattern: regexp.MustCompile(`^v1\.[01]`)}, []string{"v1.1.0", "v1.0.0"}},
+		{&releaseFilter{CreatedAfter: time.Date(2017, 8, 1, 0, 0, 0, 0, time.UTC)}, []string{"v1.2.0-rc.1", "v1.1.0"}},
+		{&releaseFilter{CreatedBefore: time.Date(2017, 8, 20, 0, 0, 0, 0, time.UTC)}, []string{"v1.0.0", "nightly"}},
+		{&releaseFilter{PreReleasesOnly: true, Limit: 1}, []string{"v1.2.0-rc.1"}},
+	}
+
+	for i, expectation := range expectations {
+		matched := filterReleases(releases, expectation.filter)
+
+		if len(matched) != len(expectation.expected) {
+			test.Fatalf("Expected filter %d to match %v but got %d releases", i, expectation.expected, len(matched))
+		}
+
+		for j, release := range matched {
+			if *release.TagName != expectation.expected[j] {
+				test.Fatalf("Expected filter %d to match %v but got %s at %d", i, expectation.expected, *release.TagName, j)
+			}
+		}
+	}
+}
@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"golang.org/x/net/context"
 	"gopkg.in/urfave/cli.v1"
+	"io"
+	"net/url"
 	"os"
 	"path"
 	"strings"
+	"time"
 )
 
 var version string
 
+// publicGitHubAPIHost is where the API of github.com lives; its uploads go to
+// a different host
+const publicGitHubAPIHost = "api.github.com"
+
 type incorrectArgumentNumberError struct {
 	expected int
 	received int
@@ -25,7 +32,27 @@ type badArgumentError struct {
 	reason   string
 }
 
+type releaseNotFoundError struct {
+	tag        string
+	checkedTag bool
+	// latest is set when looking for the latest release rather than the
+	// release for a tag
+	latest bool
+}
+
 func main() {
+	err := newApp().Run(os.Args)
+
+	// Errors with an exit code have already been printed, and grease exited,
+	// by the time Run returns. Anything else, like a GitHub API or network
+	// error, would otherwise go unreported with an exit status of 0.
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newApp() *cli.App {
 	app := cli.NewApp()
 
 	// Global flags
@@ -36,11 +63,29 @@ func main() {
 		EnvVar: "DEBUG",
 	}
 
+	configFlag := cli.StringFlag{
+		Name:   "config",
+		Usage:  "reads default flag values from this YAML file instead of " + defaultConfigFileName + " in the working directory",
+		EnvVar: "GREASE_CONFIG",
+	}
+
 	dryRunFlag := cli.BoolFlag{
 		Name:  "dry-run, n",
 		Usage: "prevents changes from being made; best used with --debug to see what changes would be made, if any",
 	}
 
+	maxRetriesFlag := cli.IntFlag{
+		Name:  "max-retries",
+		Usage: "retries GitHub API calls that fail because of server errors, network errors or rate limiting up to this many times",
+		Value: 3,
+	}
+
+	retryTimeoutFlag := cli.DurationFlag{
+		Name:  "retry-timeout",
+		Usage: "stops retrying a GitHub API call once waiting to retry it would take longer than this in total",
+		Value: 5 * time.Minute,
+	}
+
 	// Common, non-global flags
 
 	assetsFlag := cli.StringFlag{
@@ -48,6 +93,35 @@ func main() {
 		Usage: "uploads the assets at the given path (glob patterns enabled)",
 	}
 
+	onConflictFlag := cli.StringFlag{
+		Name:  "on-conflict",
+		Usage: "what to do when the release already has an asset with the same name: fail, skip or replace",
+		Value: string(assetConflictFail),
+	}
+
+	compareSizeFlag := cli.BoolFlag{
+		Name:  "compare-size",
+		Usage: "with --on-conflict=skip, replaces existing assets whose size differs from the local file",
+	}
+
+	gitHubAPIURLFlag := cli.StringFlag{
+		Name:   "github-api-url",
+		Usage:  "base URL of the GitHub API, for GitHub Enterprise (like https://github.example.com/api/v3/)",
+		EnvVar: "GITHUB_API_URL",
+	}
+
+	gitHubUploadURLFlag := cli.StringFlag{
+		Name:   "github-upload-url",
+		Usage:  "base URL for uploading assets, for GitHub Enterprise (like https://github.example.com/api/uploads/)",
+		EnvVar: "GITHUB_UPLOAD_URL",
+	}
+
+	parallelFlag := cli.IntFlag{
+		Name:  "parallel",
+		Usage: "uploads up to this many assets at the same time",
+		Value: 1,
+	}
+
 	gitHubTokenFlag := cli.StringFlag{
 		Name:   "github-token",
 		Usage:  "used to authenticate the request with the GitHub API",
@@ -64,6 +138,16 @@ func main() {
 		Usage: "marks the release as a pre-release",
 	}
 
+	noDraftFlag := cli.BoolFlag{
+		Name:  "no-draft",
+		Usage: "marks the release as published (not a draft)",
+	}
+
+	noPrereleaseFlag := cli.BoolFlag{
+		Name:  "no-pre-release, no-pre",
+		Usage: "marks the release as a full release (not a pre-release)",
+	}
+
 	nameFlag := cli.StringFlag{
 		Name:  "name",
 		Usage: "sets the name of the release, for example \"v0.4.0 - 2017-08-22\"",
@@ -74,6 +158,176 @@ func main() {
 		Usage: "sets the body of the release notes",
 	}
 
+	notesFileFlag := cli.StringFlag{
+		Name:  "notes-file",
+		Usage: "sets the body of the release notes to the contents of the file at the given path (- reads from stdin)",
+	}
+
+	generateNotesFlag := cli.BoolFlag{
+		Name:  "generate-notes",
+		Usage: "sets the body of the release notes to a list of the commits since the previous release",
+	}
+
+	previousTagFlag := cli.StringFlag{
+		Name:  "previous-tag",
+		Usage: "with --generate-notes, lists the commits since this tag instead of the previous release",
+	}
+
+	generateNotesFromFlag := cli.StringFlag{
+		Name:  "generate-notes-from",
+		Usage: "with --generate-notes, lists either the commits or the pull requests, grouped by label, since the previous release: commits or pull-requests",
+		Value: "commits",
+	}
+
+	notesFromChangelogFlag := cli.GenericFlag{
+		Name:  "notes-from-changelog",
+		Usage: "sets the body of the release notes to the section for TAG in a Keep a Changelog file; reads " + defaultChangelogPath + " unless given a path with --notes-from-changelog=PATH",
+		Value: &optionalPathValue{defaultPath: defaultChangelogPath},
+	}
+
+	nextVersionFlag := cli.BoolFlag{
+		Name:  "next-version",
+		Usage: "works out TAG from the Conventional Commits since the latest version tag, so it is left out of the positional arguments",
+	}
+
+	preReleaseSuffixFlag := cli.StringFlag{
+		Name:  "pre-release-suffix",
+		Usage: "makes the next version a numbered pre-release with this identifier, like rc for v1.3.0-rc.1",
+	}
+
+	requireSemverFlag := cli.BoolFlag{
+		Name:  "require-semver",
+		Usage: "fails unless TAG is a semantic version like v1.2.3 or v1.2.3-rc.1",
+	}
+
+	forceFlag := cli.BoolFlag{
+		Name:  "force",
+		Usage: "creates the release even if TAG is a lower version than the latest release",
+	}
+
+	annotateFlag := cli.BoolFlag{
+		Name:  "annotate",
+		Usage: "creates TAG as an annotated tag with a message before creating the release, instead of letting GitHub create a lightweight tag",
+	}
+
+	tagMessageFlag := cli.StringFlag{
+		Name:  "tag-message",
+		Usage: "with --annotate, sets the message of the tag; defaults to TAG",
+	}
+
+	taggerNameFlag := cli.StringFlag{
+		Name:  "tagger-name",
+		Usage: "with --annotate, sets the name of who made the tag; defaults to the owner of the GitHub token",
+	}
+
+	taggerEmailFlag := cli.StringFlag{
+		Name:  "tagger-email",
+		Usage: "with --annotate, sets the email of who made the tag; needed along with --tagger-name",
+	}
+
+	templateFlag := cli.BoolFlag{
+		Name:  "template",
+		Usage: "renders --name, --notes, --notes-file and --tag-message as Go templates with the tag, version, commit, date, repository, assets and environment",
+	}
+
+	deleteTagFlag := cli.BoolFlag{
+		Name:  "delete-tag",
+		Usage: "also deletes the tag from the repository",
+	}
+
+	yesFlag := cli.BoolFlag{
+		Name:  "yes, y",
+		Usage: "goes ahead without asking for confirmation",
+	}
+
+	latestFlag := cli.BoolFlag{
+		Name:  "latest",
+		Usage: "shows the latest release, so TAG is left out of the positional arguments",
+	}
+
+	showOutputFlag := cli.StringFlag{
+		Name:  "output, o",
+		Usage: "how to print the release: text, json or template=TEMPLATE for a Go template",
+		Value: "text",
+	}
+
+	listOutputFlag := cli.StringFlag{
+		Name:  "output, o",
+		Usage: "how to print the releases: table, json, tags for one tag per line or template=TEMPLATE for a Go template run for each release",
+		Value: "table",
+	}
+
+	draftsFlag := cli.BoolFlag{
+		Name:  "drafts",
+		Usage: "only lists draft releases",
+	}
+
+	preReleasesFlag := cli.BoolFlag{
+		Name:  "pre-releases",
+		Usage: "only lists pre-releases",
+	}
+
+	tagPatternFlag := cli.StringFlag{
+		Name:  "tag-pattern",
+		Usage: "only lists releases whose tag matches this regular expression",
+	}
+
+	createdBeforeFlag := cli.StringFlag{
+		Name:  "created-before",
+		Usage: "only lists releases created before this date (2017-08-23) or time (2017-08-23T15:04:05Z)",
+	}
+
+	createdAfterFlag := cli.StringFlag{
+		Name:  "created-after",
+		Usage: "only lists releases created after this date (2017-08-23) or time (2017-08-23T15:04:05Z)",
+	}
+
+	limitFlag := cli.IntFlag{
+		Name:  "limit",
+		Usage: "lists at most this many releases, newest first; 0 lists them all",
+	}
+
+	keepStableFlag := cli.IntFlag{
+		Name:  "keep-stable",
+		Usage: "keeps this many of the newest stable releases and deletes the rest; all of them are kept when not passed",
+	}
+
+	keepPreReleasesForFlag := cli.DurationFlag{
+		Name:  "keep-pre-releases-for",
+		Usage: "deletes pre-releases created longer ago than this, like 720h for 30 days; all of them are kept when not passed",
+	}
+
+	protectFlag := cli.StringFlag{
+		Name:  "protect",
+		Usage: "always keeps releases whose tag matches this regular expression",
+	}
+
+	deleteTagsFlag := cli.BoolFlag{
+		Name:  "delete-tags",
+		Usage: "also deletes the tags of the deleted releases",
+	}
+
+	requireAssetsFlag := cli.StringFlag{
+		Name:  "require-assets",
+		Usage: "refuses to publish unless each of these comma-separated asset names or glob patterns, like \"grease-*.tar.gz,checksums.txt\", matches an asset",
+	}
+
+	publishAtFlag := cli.StringFlag{
+		Name:  "publish-at",
+		Usage: "keeps the release a draft until this RFC 3339 time, like 2017-08-23T15:04:05Z, and then publishes it",
+	}
+
+	maxWaitFlag := cli.DurationFlag{
+		Name:  "max-wait",
+		Usage: "with --publish-at, refuses to wait longer than this",
+		Value: defaultMaxPublishWait,
+	}
+
+	headFlag := cli.StringFlag{
+		Name:  "head",
+		Usage: "the commit(ish) to work out the next version for; defaults to the repository's default branch",
+	}
+
 	// Hidden flags
 
 	// These flags are hidden from the user and are used to hold positional
@@ -105,8 +359,7 @@ func main() {
 
 	targetCommittishFlag := cli.StringFlag{
 		Name:   "target-commitish",
-		Usage:  "a commit-ish identifier to create the tag from",
-		Value:  "master",
+		Usage:  "a commit-ish identifier to create the tag from; defaults to the repository's default branch",
 		Hidden: true,
 	}
 
@@ -117,24 +370,52 @@ func main() {
 	createReleaseCommand := cli.Command{
 		Name:      "create-release",
 		Usage:     "creates a release on GitHub",
-		ArgsUsage: "REPO TAG COMMITISH",
+		ArgsUsage: "REPO TAG [COMMITISH]",
 		Description: `
 Creates a new GitHub release identified by TAG on the repository identified
-by REPO using the COMMITTISH identifier.
+by REPO using the COMMITTISH identifier, which defaults to the repository's
+default branch.
+
+Before anything is created, COMMITISH is resolved to a commit and, if TAG
+already exists, checked to be the commit TAG points at. A commit that is not
+on the default branch gets a warning.
+
+With --next-version, TAG is left out and worked out the same way as the
+next-version command does, from the commits up to COMMITISH.
 `,
 		Action: cmdCreateRelease,
-		Before: beforeCreateRelease,
+		Before: withConfigFile(beforeCreateRelease),
 		Flags: []cli.Flag{
 			repositoryFlag,
 			ownerFlag,
 			tagFlag,
 			targetCommittishFlag,
+			nextVersionFlag,
+			preReleaseSuffixFlag,
+			requireSemverFlag,
+			forceFlag,
+			annotateFlag,
+			tagMessageFlag,
+			taggerNameFlag,
+			taggerEmailFlag,
 			nameFlag,
+			templateFlag,
 			notesFlag,
+			notesFileFlag,
+			notesFromChangelogFlag,
+			generateNotesFlag,
+			generateNotesFromFlag,
+			previousTagFlag,
 			draftFlag,
 			prereleaseFlag,
+			noPrereleaseFlag,
 			assetsFlag,
+			onConflictFlag,
+			compareSizeFlag,
+			parallelFlag,
 			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
 		},
 	}
 
@@ -147,19 +428,84 @@ by REPO using the COMMITTISH identifier.
 		Description: `
 Updates the GitHub release identified by TAG on the repository identified by
 REPO based on the flags passed on the command line.
+
+Only the fields whose flags are passed are changed; everything else on the
+release is left as it is.
 `,
 		Action: cmdUpdateRelease,
-		Before: beforeUpdateRelease,
+		Before: withConfigFile(beforeUpdateRelease),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			tagFlag,
+			nameFlag,
+			templateFlag,
+			notesFlag,
+			notesFileFlag,
+			notesFromChangelogFlag,
+			generateNotesFlag,
+			generateNotesFromFlag,
+			previousTagFlag,
+			draftFlag,
+			noDraftFlag,
+			prereleaseFlag,
+			noPrereleaseFlag,
+			assetsFlag,
+			onConflictFlag,
+			compareSizeFlag,
+			parallelFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// ensureReleaseCommand
+
+	ensureReleaseCommand := cli.Command{
+		Name:      "ensure-release",
+		Usage:     "creates or updates a release on GitHub",
+		ArgsUsage: "REPO TAG [COMMITISH]",
+		Description: `
+Makes sure the GitHub release identified by TAG exists on the repository
+identified by REPO and matches the flags passed on the command line.
+
+If there is no release for TAG, one is created using the COMMITISH
+identifier, after the same checks as create-release. If there is one, only
+the fields whose flags are passed and differ from the release are updated;
+COMMITISH is ignored. Any assets are then uploaded.
+
+Running the command again with the same arguments is safe, which makes it
+suitable for CI jobs that may be retried.
+`,
+		Action: cmdEnsureRelease,
+		Before: withConfigFile(beforeCreateRelease),
 		Flags: []cli.Flag{
 			repositoryFlag,
 			ownerFlag,
 			tagFlag,
+			targetCommittishFlag,
+			requireSemverFlag,
+			forceFlag,
 			nameFlag,
+			templateFlag,
 			notesFlag,
+			notesFileFlag,
+			notesFromChangelogFlag,
+			generateNotesFlag,
+			generateNotesFromFlag,
+			previousTagFlag,
 			draftFlag,
+			noDraftFlag,
 			prereleaseFlag,
+			noPrereleaseFlag,
 			assetsFlag,
+			onConflictFlag,
+			compareSizeFlag,
+			parallelFlag,
 			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
 		},
 	}
 
@@ -175,13 +521,233 @@ assets for the GitHub release identified by TAG on the repository identified
 by REPO.
 `,
 		Action: cmdUploadArtifacts,
-		Before: beforeUploadArtifacts,
+		Before: withConfigFile(beforeUploadArtifacts),
 		Flags: []cli.Flag{
 			globPatternFlag,
 			repositoryFlag,
 			ownerFlag,
 			tagFlag,
+			onConflictFlag,
+			compareSizeFlag,
+			parallelFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// rollingReleaseCommand
+
+	rollingReleaseCommand := cli.Command{
+		Name:      "rolling-release",
+		Usage:     "moves a release like nightly on GitHub to a new commit",
+		ArgsUsage: "REPO TAG [COMMITISH]",
+		Description: `
+Keeps a continuously updated release identified by TAG, like "nightly", on
+the repository identified by REPO. The tag is created or force-moved to the
+commit COMMITISH points at, the release is created if there isn't one yet,
+and its notes are rewritten to describe the new commit.
+
+The assets replace all of the release's existing assets. They are uploaded
+under temporary names first; only once every upload succeeded are the old
+assets deleted and the new ones renamed, so a failed upload leaves the
+previous build in place.
+`,
+		Action: cmdRollingRelease,
+		Before: withConfigFile(beforeCreateRelease),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			tagFlag,
+			targetCommittishFlag,
+			nameFlag,
+			notesFlag,
+			notesFileFlag,
+			prereleaseFlag,
+			noPrereleaseFlag,
+			assetsFlag,
+			parallelFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// listReleasesCommand
+
+	listReleasesCommand := cli.Command{
+		Name:      "list-releases",
+		Usage:     "lists the releases on GitHub",
+		ArgsUsage: "REPO",
+		Description: `
+Lists the GitHub releases of the repository identified by REPO, newest first,
+going through every page of results. The filter flags can be combined, in
+which case a release has to match all of them.
+`,
+		Action: cmdListReleases,
+		Before: withConfigFile(beforeRepositoryCommand),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			draftsFlag,
+			preReleasesFlag,
+			tagPatternFlag,
+			createdBeforeFlag,
+			createdAfterFlag,
+			limitFlag,
+			listOutputFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// deleteReleaseCommand
+
+	deleteReleaseCommand := cli.Command{
+		Name:      "delete-release",
+		Usage:     "deletes a release on GitHub",
+		ArgsUsage: "REPO TAG",
+		Description: `
+Deletes the GitHub release identified by TAG on the repository identified by
+REPO, along with its assets. With --delete-tag, the tag is deleted as well.
+
+What is going to be deleted is printed first, and you are asked to confirm
+unless --yes is passed. With --dry-run nothing is deleted.
+`,
+		Action: cmdDeleteRelease,
+		Before: withConfigFile(beforeUpdateRelease),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			tagFlag,
+			deleteTagFlag,
+			yesFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// showReleaseCommand
+
+	showReleaseCommand := cli.Command{
+		Name:      "show-release",
+		Usage:     "prints a release on GitHub and its assets",
+		ArgsUsage: "REPO TAG",
+		Description: `
+Prints the GitHub release identified by TAG on the repository identified by
+REPO, along with the size, content type, download count and URLs of each of
+its assets. With --latest, TAG is left out and the latest release is shown.
+
+Exits with status 1 if there is no such release, so scripts can check whether
+a release exists.
+`,
+		Action: cmdShowRelease,
+		Before: withConfigFile(beforeShowRelease),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			tagFlag,
+			latestFlag,
+			showOutputFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// publishReleaseCommand
+
+	publishReleaseCommand := cli.Command{
+		Name:      "publish-release",
+		Usage:     "publishes a draft release on GitHub once it is complete",
+		ArgsUsage: "REPO TAG",
+		Description: `
+Publishes the draft release identified by TAG on the repository identified by
+REPO, which is useful when several CI jobs upload assets to the same draft.
+
+The release is only published if its notes aren't empty, every asset finished
+uploading and every name or pattern in --require-assets matches an asset.
+Otherwise each problem is printed and the release is left as a draft. With
+--no-pre-release the release is also marked as a full release.
+
+With --publish-at, the release stays a draft until the given time and is
+then checked again and published. The release is read back afterwards to
+make sure it was published.
+`,
+		Action: cmdPublishRelease,
+		Before: withConfigFile(beforeUpdateRelease),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			tagFlag,
+			requireAssetsFlag,
+			noPrereleaseFlag,
+			publishAtFlag,
+			maxWaitFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// pruneReleasesCommand
+
+	pruneReleasesCommand := cli.Command{
+		Name:      "prune-releases",
+		Usage:     "deletes old releases on GitHub according to a retention policy",
+		ArgsUsage: "REPO",
+		Description: `
+Applies a retention policy to the releases of the repository identified by
+REPO and deletes the releases it doesn't keep. Stable releases beyond the
+newest --keep-stable and pre-releases older than --keep-pre-releases-for are
+deleted; drafts and releases whose tag matches --protect are always kept.
+
+The plan for every release is printed first, and you are asked to confirm
+unless --yes is passed. With --dry-run nothing is deleted.
+`,
+		Action: cmdPruneReleases,
+		Before: withConfigFile(beforeRepositoryCommand),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			keepStableFlag,
+			keepPreReleasesForFlag,
+			protectFlag,
+			deleteTagsFlag,
+			yesFlag,
+			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
+		},
+	}
+
+	// nextVersionCommand
+
+	nextVersionCommand := cli.Command{
+		Name:      "next-version",
+		Usage:     "prints the next version based on the Conventional Commits since the latest release",
+		ArgsUsage: "REPO",
+		Description: `
+Finds the highest version tag that isn't a pre-release on the repository
+identified by REPO and reads the commits made since then in the Conventional
+Commits format. Breaking changes bump the major version, feat commits the
+minor version and fix commits the patch version. The resulting tag is printed
+on its own line.
+
+Exits with status 1 if none of the commits call for a release.
+`,
+		Action: cmdNextVersion,
+		Before: withConfigFile(beforeRepositoryCommand),
+		Flags: []cli.Flag{
+			repositoryFlag,
+			ownerFlag,
+			headFlag,
+			preReleaseSuffixFlag,
 			gitHubTokenFlag,
+			gitHubAPIURLFlag,
+			gitHubUploadURLFlag,
 		},
 	}
 
@@ -198,7 +764,7 @@ This command is designed for trouble-shooting the use of a glob pattern when
 using them to specify assets to upload.
 `,
 		Action: cmdListFiles,
-		Before: beforeListFiles,
+		Before: withConfigFile(beforeListFiles),
 		Flags: []cli.Flag{
 			globPatternFlag,
 		},
@@ -208,18 +774,31 @@ using them to specify assets to upload.
 	app.Version = version
 
 	app.Flags = []cli.Flag{
+		configFlag,
 		debugFlag,
 		dryRunFlag,
+		maxRetriesFlag,
+		retryTimeoutFlag,
 	}
 
+	app.Before = beforeApp
+
 	app.Commands = []cli.Command{
 		createReleaseCommand,
 		updateReleaseCommand,
+		ensureReleaseCommand,
+		rollingReleaseCommand,
+		publishReleaseCommand,
 		uploadArtifactsCommand,
+		listReleasesCommand,
+		deleteReleaseCommand,
+		pruneReleasesCommand,
+		showReleaseCommand,
+		nextVersionCommand,
 		listFilesCommand,
 	}
 
-	app.Run(os.Args)
+	return app
 }
 
 /*
@@ -250,15 +829,26 @@ constraints.
 
 func beforeCreateRelease(ctx *cli.Context) error {
 	debug := ctx.GlobalBool("debug")
-	// Expected positional arguments (3): REPO TAG COMMITTISH
-	err := validatePositionalArgumentCount(ctx, 3)
+	nextVersion := ctx.Bool("next-version")
+
+	if ctx.IsSet("pre-release-suffix") && !nextVersion {
+		return &badArgumentError{argument: "--pre-release-suffix", reason: "can only be used with --next-version"}
+	}
+
+	// Expected positional arguments (3): REPO TAG COMMITTISH, or (2) with
+	// --next-version: REPO COMMITTISH
+	expected := 3
+
+	if nextVersion {
+		expected = 2
+	}
+
+	arguments, err := commitishPositionalArguments(ctx, expected)
 
 	if err != nil {
 		return err
 	}
 
-	arguments := ctx.Args()
-
 	repo := arguments.Get(0)
 	repoOwner, repoName, err := splitRepositoryName(repo)
 
@@ -286,18 +876,30 @@ func beforeCreateRelease(ctx *cli.Context) error {
 		fmt.Printf("GitHub repository name is: %s\n", repo)
 	}
 
-	tag := arguments.Get(1)
-	err = ctx.Set("tag", tag)
+	if !nextVersion {
+		tag := arguments.Get(1)
+		err = ctx.Set("tag", tag)
 
-	if err != nil {
-		return err
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			fmt.Printf("Git tag is: %s\n", tag)
+		}
+
+		_, err = releaseTagVersion(ctx, tag)
+
+		if err != nil {
+			return err
+		}
 	}
 
-	if debug {
-		fmt.Printf("Git tag is: %s\n", tag)
+	if len(arguments) < expected {
+		return nil
 	}
 
-	commitish := arguments.Get(2)
+	commitish := arguments.Get(expected - 1)
 	err = ctx.Set("target-commitish", commitish)
 
 	if err != nil {
@@ -314,14 +916,12 @@ func beforeCreateRelease(ctx *cli.Context) error {
 func beforeUpdateRelease(ctx *cli.Context) error {
 	debug := ctx.GlobalBool("debug")
 	// Expected positional arguments (2): REPO TAG
-	err := validatePositionalArgumentCount(ctx, 2)
+	arguments, err := repoPositionalArguments(ctx, 2)
 
 	if err != nil {
 		return err
 	}
 
-	arguments := ctx.Args()
-
 	repo := arguments.Get(0)
 	repoOwner, repoName, err := splitRepositoryName(repo)
 
@@ -366,14 +966,12 @@ func beforeUpdateRelease(ctx *cli.Context) error {
 func beforeUploadArtifacts(ctx *cli.Context) error {
 	debug := ctx.GlobalBool("debug")
 	// Expected positional arguments (3): REPO TAG GLOB_PATTERN
-	err := validatePositionalArgumentCount(ctx, 3)
+	arguments, err := repoPositionalArguments(ctx, 3)
 
 	if err != nil {
 		return err
 	}
 
-	arguments := ctx.Args()
-
 	repo := arguments.Get(0)
 	repoOwner, repoName, err := splitRepositoryName(repo)
 
@@ -426,35 +1024,135 @@ func beforeUploadArtifacts(ctx *cli.Context) error {
 	return nil
 }
 
-func beforeListFiles(ctx *cli.Context) error {
+func beforeShowRelease(ctx *cli.Context) error {
 	debug := ctx.GlobalBool("debug")
+	latest := ctx.Bool("latest")
+	// Expected positional arguments (2): REPO TAG, or (1) with --latest: REPO
+	expected := 2
 
-	// Expected positional arguments (1): GLOB_PATTERN
-	err := validatePositionalArgumentCount(ctx, 1)
+	if latest {
+		expected = 1
+	}
+
+	arguments, err := repoPositionalArguments(ctx, expected)
 
 	if err != nil {
 		return err
 	}
 
-	arguments := ctx.Args()
+	repo := arguments.Get(0)
+	repoOwner, repoName, err := splitRepositoryName(repo)
 
-	globPattern := arguments.Get(0)
-	err = ctx.Set("glob-pattern", globPattern)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Set("owner", repoOwner)
 
 	if err != nil {
 		return err
 	}
 
 	if debug {
-		fmt.Printf("Glob pattern is: %s\n", globPattern)
+		fmt.Printf("GitHub repository owner is: %s\n", repoOwner)
 	}
 
-	return nil
-}
+	err = ctx.Set("repository", repoName)
 
-func cmdCreateRelease(ctx *cli.Context) error {
-	dry := ctx.GlobalBool("dry-run")
-	debug := ctx.GlobalBool("debug")
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Printf("GitHub repository name is: %s\n", repo)
+	}
+
+	if !latest {
+		tag := arguments.Get(1)
+		err = ctx.Set("tag", tag)
+
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			fmt.Printf("Git tag is: %s\n", tag)
+		}
+	}
+
+	return nil
+}
+
+// beforeRepositoryCommand is the before function of the commands whose only
+// positional argument is REPO
+func beforeRepositoryCommand(ctx *cli.Context) error {
+	debug := ctx.GlobalBool("debug")
+	// Expected positional arguments (1): REPO
+	arguments, err := repoPositionalArguments(ctx, 1)
+
+	if err != nil {
+		return err
+	}
+
+	repo := arguments.Get(0)
+	repoOwner, repoName, err := splitRepositoryName(repo)
+
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Set("owner", repoOwner)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Printf("GitHub repository owner is: %s\n", repoOwner)
+	}
+
+	err = ctx.Set("repository", repoName)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Printf("GitHub repository name is: %s\n", repo)
+	}
+
+	return nil
+}
+
+func beforeListFiles(ctx *cli.Context) error {
+	debug := ctx.GlobalBool("debug")
+
+	// Expected positional arguments (1): GLOB_PATTERN
+	err := validatePositionalArgumentCount(ctx, 1)
+
+	if err != nil {
+		return err
+	}
+
+	arguments := ctx.Args()
+
+	globPattern := arguments.Get(0)
+	err = ctx.Set("glob-pattern", globPattern)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Printf("Glob pattern is: %s\n", globPattern)
+	}
+
+	return nil
+}
+
+func cmdCreateRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
 
 	if debug {
 		fmt.Println("Preparing to create release")
@@ -466,247 +1164,1259 @@ func cmdCreateRelease(ctx *cli.Context) error {
 	tagName := ctx.String("tag")
 	targetCommitish := ctx.String("target-commitish")
 	releaseName := ctx.String("name")
-	releaseBody := ctx.String("notes")
 	draft := ctx.Bool("draft")
-	preRelease := ctx.Bool("pre-release")
 
 	assetGlobPattern := ctx.String("assets")
 
 	gitHubToken := ctx.String("github-token")
 
-	repo := &gitHubRepo{
-		Name:  repoName,
-		Owner: repoOwner,
-	}
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	targetCommitish, err = resolveTargetCommitish(context.Background(), ctx, repo, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("next-version") {
+		tagName, err = calculateNextVersion(context.Background(), repo, targetCommitish, ctx.String("pre-release-suffix"), gitHubToken, debug)
+
+		if err != nil {
+			return err
+		}
+
+		// The notes are looked up by tag
+		err = ctx.Set("tag", tagName)
+
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			fmt.Printf("Next version is: %s\n", tagName)
+		}
+	}
+
+	version, err := releaseTagVersion(ctx, tagName)
+
+	if err != nil {
+		return err
+	}
+
+	if version != nil && !ctx.Bool("force") {
+		err = checkVersionNotLower(context.Background(), repo, version, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	targetSHA, err := verifyReleaseTarget(context.Background(), repo, tagName, targetCommitish, gitHubToken, debug)
+
+	if err != nil {
+		return err
+	}
+
+	preRelease, err := releasePreRelease(ctx, version)
+
+	if err != nil {
+		return err
+	}
+
+	if preRelease == nil {
+		preRelease = new(bool)
+	}
+
+	templateCtx := releaseTemplateContext(ctx, repo)
+
+	releaseName, err = renderTemplate("--name", releaseName, templateCtx)
+
+	if err != nil {
+		return err
+	}
+
+	tagOptions, err := newAnnotatedTagOptions(ctx, templateCtx)
+
+	if err != nil {
+		return err
+	}
+
+	notes, err := releaseNotes(ctx, repo, templateCtx)
+
+	if err != nil {
+		return err
+	}
+
+	releaseBody := ""
+
+	if notes != nil {
+		releaseBody = *notes
+	}
+
+	release := &gitHubRelease{
+		TagName:         &tagName,
+		TargetCommitish: &targetSHA,
+		Name:            &releaseName,
+		Body:            &releaseBody,
+		Draft:           &draft,
+		PreRelease:      preRelease,
+	}
+
+	assets, err := findFiles(assetGlobPattern)
+
+	if err != nil {
+		return err
+	}
+
+	uploadOptions, err := newAssetUploadOptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Println("Release Creation Settings")
+		fmt.Println("=========================")
+		printRepoDebugStatements(repo)
+		printReleaseDebugStatements(release)
+
+		if tagOptions != nil {
+			printAnnotatedTagDebugStatements(tagOptions)
+		}
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	netCtx := context.Background()
+
+	createdTag := false
+
+	if tagOptions != nil {
+		createdTag, err = createAnnotatedTag(netCtx, repo, tagName, targetSHA, tagOptions, gitHubToken, debug)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	releaseId, err := repo.CreateRelease(netCtx, release, gitHubToken)
+
+	if err != nil {
+		// Don't leave the new tag behind without a release
+		if createdTag {
+			deleteErr := repo.DeleteTagRef(netCtx, tagName, gitHubToken)
+
+			if deleteErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not delete tag %s after creating the release failed: %v\n", tagName, deleteErr)
+			}
+		}
+
+		return err
+	}
+
+	if debug {
+		fmt.Printf("Created release (id: %d)!\n", *releaseId)
+		fmt.Println("Preparing to upload any assets")
+	}
+
+	results := uploadAssets(netCtx, repo, *releaseId, assets, gitHubToken, uploadOptions)
+
+	return reportAssetUploads(results)
+}
+
+func cmdUpdateRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to update release")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+
+	tagName := ctx.String("tag")
+
+	assetGlobPattern := ctx.String("assets")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	// Only the fields whose flags were passed are sent to GitHub so that
+	// the update does not blank out the rest of the release
+	release := &gitHubRelease{
+		TagName: &tagName,
+	}
+
+	templateCtx := releaseTemplateContext(ctx, repo)
+
+	if ctx.IsSet("name") {
+		releaseName, err := renderTemplate("--name", ctx.String("name"), templateCtx)
+
+		if err != nil {
+			return err
+		}
+
+		release.Name = &releaseName
+	}
+
+	release.Body, err = releaseNotes(ctx, repo, templateCtx)
+
+	if err != nil {
+		return err
+	}
+
+	draft, err := toggleFlagValue(ctx, "draft", "no-draft")
+
+	if err != nil {
+		return err
+	}
+
+	release.Draft = draft
+
+	preRelease, err := toggleFlagValue(ctx, "pre-release", "no-pre-release")
+
+	if err != nil {
+		return err
+	}
+
+	release.PreRelease = preRelease
+
+	assets, err := findFiles(assetGlobPattern)
+
+	if err != nil {
+		return err
+	}
+
+	uploadOptions, err := newAssetUploadOptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Println("Release Update Settings")
+		fmt.Println("=========================")
+		printRepoDebugStatements(repo)
+		printReleaseDebugStatements(release)
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	netCtx := context.Background()
+
+	releaseId, _, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if releaseId == nil {
+		// A tag pushed without a release only exists as a ref, so the
+		// release is created from the commit the tag points at. Drafts are
+		// found by the lookup above, so this only happens when the tag has
+		// no release at all.
+		ref, err := repo.FindTagRef(netCtx, tagName, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		if ref == nil {
+			return &releaseNotFoundError{tag: tagName, checkedTag: true}
+		}
+
+		sha, err := tagCommitSHA(netCtx, repo, ref, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			fmt.Printf("No release found for tag %s; creating one from commit %s\n", tagName, sha)
+		}
+
+		release.TargetCommitish = &sha
+		releaseId, err = repo.CreateRelease(netCtx, release, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created release (id: %d) for existing tag %s at %s\n", *releaseId, tagName, shortSHA(sha))
+	} else {
+		_, err = repo.UpdateRelease(netCtx, *releaseId, release, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Updated release (id: %d)\n", *releaseId)
+	}
+
+	if debug {
+		fmt.Println("Preparing to upload any assets")
+	}
+
+	results := uploadAssets(netCtx, repo, *releaseId, assets, gitHubToken, uploadOptions)
+
+	return reportAssetUploads(results)
+}
+
+func cmdEnsureRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to ensure release")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+
+	tagName := ctx.String("tag")
+	targetCommitish := ctx.String("target-commitish")
+
+	assetGlobPattern := ctx.String("assets")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	netCtx := context.Background()
+
+	releaseId, existingRelease, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if releaseId == nil {
+		targetCommitish, err = resolveTargetCommitish(netCtx, ctx, repo, gitHubToken)
+	} else {
+		// COMMITISH is ignored for an existing release, so generated notes
+		// and templates end at its tag rather than at a branch that has
+		// moved on since, like update-release does
+		targetCommitish = ""
+		err = ctx.Set("target-commitish", targetCommitish)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	release := &gitHubRelease{
+		TagName:         &tagName,
+		TargetCommitish: &targetCommitish,
+	}
+
+	templateCtx := releaseTemplateContext(ctx, repo)
+
+	if ctx.IsSet("name") {
+		releaseName, err := renderTemplate("--name", ctx.String("name"), templateCtx)
+
+		if err != nil {
+			return err
+		}
+
+		release.Name = &releaseName
+	}
+
+	release.Body, err = releaseNotes(ctx, repo, templateCtx)
+
+	if err != nil {
+		return err
+	}
+
+	draft, err := toggleFlagValue(ctx, "draft", "no-draft")
+
+	if err != nil {
+		return err
+	}
+
+	release.Draft = draft
+
+	version, err := releaseTagVersion(ctx, tagName)
+
+	if err != nil {
+		return err
+	}
+
+	preRelease, err := releasePreRelease(ctx, version)
+
+	if err != nil {
+		return err
+	}
+
+	release.PreRelease = preRelease
+
+	assets, err := findFiles(assetGlobPattern)
+
+	if err != nil {
+		return err
+	}
+
+	uploadOptions, err := newAssetUploadOptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Println("Release Settings")
+		fmt.Println("=========================")
+		printRepoDebugStatements(repo)
+		printReleaseDebugStatements(release)
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	if releaseId == nil {
+		if debug {
+			fmt.Printf("No release found for tag %s\n", tagName)
+		}
+
+		if version != nil && !ctx.Bool("force") {
+			err = checkVersionNotLower(netCtx, repo, version, gitHubToken)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		targetSHA, err := verifyReleaseTarget(netCtx, repo, tagName, targetCommitish, gitHubToken, debug)
+
+		if err != nil {
+			return err
+		}
+
+		release.TargetCommitish = &targetSHA
+
+		releaseId, err = repo.CreateRelease(netCtx, release, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created release (id: %d)\n", *releaseId)
+	} else {
+		changes := releaseChanges(existingRelease, release)
+
+		if changes == nil {
+			fmt.Printf("Release (id: %d) is already up to date\n", *releaseId)
+		} else {
+			if debug {
+				fmt.Println("Release Changes")
+				fmt.Println("=========================")
+				printReleaseDebugStatements(changes)
+			}
+
+			_, err = repo.UpdateRelease(netCtx, *releaseId, changes, gitHubToken)
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Updated release (id: %d)\n", *releaseId)
+		}
+	}
+
+	if debug {
+		fmt.Println("Preparing to upload any assets")
+	}
+
+	results := uploadAssets(netCtx, repo, *releaseId, assets, gitHubToken, uploadOptions)
+
+	return reportAssetUploads(results)
+}
+
+func cmdRollingRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to update rolling release")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+
+	tagName := ctx.String("tag")
+	targetCommitish := ctx.String("target-commitish")
+
+	assetGlobPattern := ctx.String("assets")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	targetCommitish, err = resolveTargetCommitish(context.Background(), ctx, repo, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	notes, err := releaseNotes(ctx, repo, nil)
+
+	if err != nil {
+		return err
+	}
+
+	preRelease, err := toggleFlagValue(ctx, "pre-release", "no-pre-release")
+
+	if err != nil {
+		return err
+	}
+
+	assets, err := findFiles(assetGlobPattern)
+
+	if err != nil {
+		return err
+	}
+
+	uploadOptions, err := newAssetUploadOptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	netCtx := context.Background()
+
+	commit, err := repo.GetCommit(netCtx, targetCommitish, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	sha := stringValue(commit.SHA)
+	body := renderRollingNotes(tagName, targetCommitish, commit, notes)
+
+	release := &gitHubRelease{
+		TagName:    &tagName,
+		Body:       &body,
+		PreRelease: preRelease,
+	}
+
+	if ctx.IsSet("name") {
+		releaseName := ctx.String("name")
+		release.Name = &releaseName
+	}
+
+	tagRef, err := repo.FindTagRef(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	// An annotated tag points at a tag object rather than at the commit
+	tagSHA := ""
+
+	if tagRef != nil {
+		tagSHA, err = tagCommitSHA(netCtx, repo, tagRef, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	releaseId, existingRelease, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Println("Rolling Release Settings")
+		fmt.Println("=========================")
+		printRepoDebugStatements(repo)
+		fmt.Printf("Commit:\t\t\t%s\n", sha)
+
+		if tagRef == nil {
+			fmt.Println("Tag:\t\t\t(will be created)")
+		} else {
+			fmt.Printf("Current Tag Commit:\t%s\n", tagSHA)
+		}
+
+		printReleaseDebugStatements(release)
+		printAssetDebugStatements(assets)
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	// The tag has to exist before the release, or GitHub would create it
+	// from the default branch
+	if tagRef == nil {
+		err = repo.CreateTagRef(netCtx, tagName, sha, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created tag %s at %s\n", tagName, shortSHA(sha))
+	}
+
+	if releaseId == nil {
+		releaseId, err = repo.CreateRelease(netCtx, release, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created release (id: %d)\n", *releaseId)
+	}
+
+	if len(assets) == 0 {
+		// Leave the previous build's assets alone rather than deleting them
+		// all when nothing was built
+		fmt.Println("No assets found to upload; keeping the existing assets")
+	}
+
+	// Upload the new assets next to the old ones first so that nothing is
+	// lost if an upload fails
+	prefix := rollingAssetPrefix(sha)
+	uploadOptions.OnConflict = assetConflictReplace
+	uploadOptions.NamePrefix = prefix
+
+	results := uploadAssets(netCtx, repo, *releaseId, assets, gitHubToken, uploadOptions)
+	err = reportAssetUploads(results)
+
+	if err != nil {
+		cleanupErr := deleteRollingAssets(netCtx, repo, *releaseId, prefix, gitHubToken)
+
+		if cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not delete the assets uploaded under temporary names: %v\n", cleanupErr)
+		}
+
+		return err
+	}
+
+	if tagRef != nil && tagSHA != sha {
+		err = repo.UpdateTagRef(netCtx, tagName, sha, true, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Moved tag %s to %s\n", tagName, shortSHA(sha))
+
+		if stringValue(tagRef.ObjectType) != "commit" {
+			fmt.Fprintf(os.Stderr, "Warning: tag %s was an annotated tag and is now a lightweight tag\n", tagName)
+		}
+	}
+
+	if existingRelease != nil {
+		changes := releaseChanges(existingRelease, release)
+
+		if changes != nil {
+			_, err = repo.UpdateRelease(netCtx, *releaseId, changes, gitHubToken)
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Updated release (id: %d)\n", *releaseId)
+		}
+	}
+
+	if len(assets) > 0 {
+		err = swapRollingAssets(netCtx, repo, *releaseId, prefix, gitHubToken, debug)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Rolling release %s now points at %s\n", tagName, shortSHA(sha))
+
+	return nil
+}
+
+func cmdUploadArtifacts(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to upload assets")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+	tagName := ctx.String("tag")
+
+	assetGlobPattern := ctx.String("glob-pattern")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	assets, err := findFiles(assetGlobPattern)
+
+	if err != nil {
+		return err
+	}
+
+	uploadOptions, err := newAssetUploadOptions(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		fmt.Println("Asset Upload Settings")
+		fmt.Println("=====================")
+		printRepoDebugStatements(repo)
+		fmt.Printf("Tag:\t\t\t%s\n", tagName)
+		printAssetDebugStatements(assets)
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	netCtx := context.Background()
+
+	releaseId, _, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if releaseId == nil {
+		return &releaseNotFoundError{tag: tagName}
+	}
+
+	results := uploadAssets(netCtx, repo, *releaseId, assets, gitHubToken, uploadOptions)
+
+	return reportAssetUploads(results)
+}
+
+func cmdDeleteRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to delete release")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+	tagName := ctx.String("tag")
+	deleteTag := ctx.Bool("delete-tag")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		printRepoDebugStatements(repo)
+	}
+
+	netCtx := context.Background()
+
+	releaseId, release, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	var tagRef *gitHubRef
+
+	if deleteTag {
+		tagRef, err = repo.FindTagRef(netCtx, tagName, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if releaseId == nil && tagRef == nil {
+		return &releaseNotFoundError{tag: tagName, checkedTag: deleteTag}
+	}
+
+	fmt.Printf("The following will be deleted from %s/%s:\n", repo.Owner, repo.Name)
+
+	if releaseId != nil {
+		assets, err := repo.ListReleaseAssets(netCtx, *releaseId, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		name := stringValue(release.Name)
+
+		if name == "" {
+			name = tagName
+		}
+
+		fmt.Printf("\t- Release \"%s\" (id: %d) with %d assets\n", name, *releaseId, len(assets))
+
+		for _, asset := range assets {
+			fmt.Printf("\t\t- %s\n", *asset.Name)
+		}
+	} else {
+		fmt.Printf("\t- (No release for tag %s)\n", tagName)
+	}
+
+	if tagRef != nil {
+		fmt.Printf("\t- Tag %s (%s)\n", tagName, shortSHA(stringValue(tagRef.ObjectSHA)))
+	} else if deleteTag {
+		fmt.Printf("\t- (No tag %s)\n", tagName)
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	err = confirm(ctx, "Delete these")
+
+	if err != nil {
+		return err
+	}
+
+	if releaseId != nil {
+		err = repo.DeleteRelease(netCtx, *releaseId, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted release (id: %d)\n", *releaseId)
+	}
+
+	if tagRef != nil {
+		err = repo.DeleteTagRef(netCtx, tagName, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted tag %s\n", tagName)
+	}
+
+	return nil
+}
+
+func cmdPublishRelease(ctx *cli.Context) error {
+	dry := ctx.GlobalBool("dry-run")
+	debug := ctx.GlobalBool("debug")
+
+	if debug {
+		fmt.Println("Preparing to publish release")
+	}
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+	tagName := ctx.String("tag")
+
+	gitHubToken := ctx.String("github-token")
+
+	checks, err := newPublishChecks(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	schedule, err := newPublishSchedule(ctx, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	netCtx, stop := cancelOnSignal(context.Background())
+	defer stop()
+
+	_, release, err := repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if release == nil {
+		return &releaseNotFoundError{tag: tagName}
+	}
+
+	if !boolValue(release.Draft) {
+		fmt.Printf("Release (id: %d) for %s is already published\n", *release.ID, tagName)
+		return nil
+	}
+
+	err = checkPublishable(netCtx, repo, release, checks, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	published := false
+	changes := &gitHubRelease{
+		TagName: &tagName,
+		Draft:   &published,
+	}
+
+	if ctx.Bool("no-pre-release") {
+		changes.PreRelease = new(bool)
+	}
+
+	if debug {
+		fmt.Println("Release Changes")
+		fmt.Println("=========================")
+		printRepoDebugStatements(repo)
+		printReleaseDebugStatements(changes)
+
+		if schedule != nil {
+			fmt.Printf("Publish At:\t\t%s\n", schedule.At.Format(time.RFC3339))
+		}
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	if schedule != nil {
+		if schedule.At.After(time.Now()) {
+			fmt.Printf("Waiting until %s to publish release (id: %d) for %s\n", schedule.At.Format(time.RFC3339), *release.ID, tagName)
+		}
+
+		var progress io.Writer
+
+		if debug {
+			progress = os.Stdout
+		}
+
+		err = waitUntil(netCtx, schedule.At, progress)
+
+		if err != nil {
+			return &publishWaitCancelledError{tag: tagName, at: schedule.At}
+		}
+
+		// The release may have changed while waiting, so it is checked
+		// again right before publishing
+		release, err = repo.GetRelease(netCtx, *release.ID, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+
+		if !boolValue(release.Draft) {
+			fmt.Printf("Release (id: %d) for %s was published while waiting\n", *release.ID, tagName)
+			return nil
+		}
+
+		err = checkPublishable(netCtx, repo, release, checks, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = repo.UpdateRelease(netCtx, *release.ID, changes, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	release, err = repo.GetRelease(netCtx, *release.ID, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	if boolValue(release.Draft) {
+		return &publishNotVerifiedError{tag: tagName}
+	}
+
+	fmt.Printf("Published release (id: %d) for %s\n", *release.ID, tagName)
+
+	return nil
+}
+
+func cmdListReleases(ctx *cli.Context) error {
+	debug := ctx.GlobalBool("debug")
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+
+	gitHubToken := ctx.String("github-token")
+
+	format, err := parseOutputFormat(ctx.String("output"), "table", "json", "tags")
 
-	release := &gitHubRelease{
-		TagName:         &tagName,
-		TargetCommitish: &targetCommitish,
-		Name:            &releaseName,
-		Body:            &releaseBody,
-		Draft:           &draft,
-		PreRelease:      &preRelease,
+	if err != nil {
+		return err
 	}
 
-	assets, err := findFiles(assetGlobPattern)
+	filter, err := newReleaseFilter(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	if debug {
-		fmt.Println("Release Creation Settings")
-		fmt.Println("=========================")
-		printRepoDebugStatements(repo)
-		printReleaseDebugStatements(release)
-	}
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
 
-	if dry {
-		fmt.Println("Dry run specified. Exiting.")
-		return nil
+	if err != nil {
+		return err
 	}
 
-	netCtx := context.Background()
-
-	releaseId, err := repo.CreateRelease(netCtx, release, gitHubToken)
+	releases, err := repo.ListReleases(context.Background(), gitHubToken)
 
 	if err != nil {
 		return err
 	}
 
+	matched := filterReleases(releases, filter)
+
 	if debug {
-		fmt.Printf("Created release (id: %d)!\n", *releaseId)
-		fmt.Println("Preparing to upload any assets")
+		fmt.Printf("%d of %d releases match\n", len(matched), len(releases))
 	}
 
-	for _, assetPath := range assets {
-		filename := path.Base(assetPath)
-		file, err := os.Open(assetPath)
+	summaries := make([]*releaseSummary, len(matched))
 
-		if err != nil {
-			fmt.Printf("Failed to open %s. Skipping.\n", assetPath)
-			continue
-		}
+	for i, release := range matched {
+		summaries[i] = newReleaseSummary(release)
+	}
 
-		if debug {
-			fmt.Printf("Uploading asset at %s as %s\n", assetPath, filename)
+	switch format.Name {
+	case "json":
+		return writeJSON(os.Stdout, summaries)
+	case "tags":
+		for _, summary := range summaries {
+			fmt.Println(summary.TagName)
 		}
+	case "template":
+		for _, summary := range summaries {
+			err = writeTemplate(os.Stdout, format, summary)
 
-		err = repo.UploadReleaseAsset(netCtx, *releaseId, file, filename, gitHubToken)
-
-		if err != nil {
-			fmt.Printf("Error while uploading asset at %s\n", filename)
-			fmt.Println(err)
+			if err != nil {
+				return err
+			}
 		}
+	default:
+		writeReleaseTable(os.Stdout, summaries)
 	}
 
 	return nil
 }
 
-func cmdUpdateRelease(ctx *cli.Context) error {
+func cmdPruneReleases(ctx *cli.Context) error {
 	dry := ctx.GlobalBool("dry-run")
 	debug := ctx.GlobalBool("debug")
 
 	if debug {
-		fmt.Println("Preparing to update release")
+		fmt.Println("Preparing to prune releases")
 	}
 
 	repoName := ctx.String("repository")
 	repoOwner := ctx.String("owner")
-
-	tagName := ctx.String("tag")
-	releaseName := ctx.String("name")
-	releaseBody := ctx.String("notes")
-	draft := ctx.Bool("draft")
-	preRelease := ctx.Bool("pre-release")
-
-	assetGlobPattern := ctx.String("assets")
+	deleteTags := ctx.Bool("delete-tags")
 
 	gitHubToken := ctx.String("github-token")
 
-	repo := &gitHubRepo{
-		Name:  repoName,
-		Owner: repoOwner,
-	}
+	policy, err := newRetentionPolicy(ctx)
 
-	release := &gitHubRelease{
-		TagName:    &tagName,
-		Name:       &releaseName,
-		Body:       &releaseBody,
-		Draft:      &draft,
-		PreRelease: &preRelease,
+	if err != nil {
+		return err
 	}
 
-	assets, err := findFiles(assetGlobPattern)
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
 
 	if err != nil {
 		return err
 	}
 
 	if debug {
-		fmt.Println("Release Update Settings")
-		fmt.Println("=========================")
 		printRepoDebugStatements(repo)
-		printReleaseDebugStatements(release)
-	}
-
-	if dry {
-		fmt.Println("Dry run specified. Exiting.")
-		return nil
 	}
 
 	netCtx := context.Background()
 
-	releaseId, err := repo.GetReleaseIdByTag(netCtx, *release.TagName, gitHubToken)
+	releases, err := repo.ListReleases(netCtx, gitHubToken)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = repo.UpdateRelease(netCtx, *releaseId, release, gitHubToken)
+	decisions := planPrune(releases, policy)
+	writePrunePlan(os.Stdout, decisions)
+
+	var doomed []*gitHubRelease
+
+	for _, decision := range decisions {
+		if decision.Delete {
+			doomed = append(doomed, decision.Release)
+		}
+	}
+
+	if len(doomed) == 0 {
+		fmt.Println("Nothing to prune")
+		return nil
+	}
+
+	if deleteTags {
+		fmt.Printf("%d of %d releases and their tags will be deleted\n", len(doomed), len(releases))
+	} else {
+		fmt.Printf("%d of %d releases will be deleted\n", len(doomed), len(releases))
+	}
+
+	if dry {
+		fmt.Println("Dry run specified. Exiting.")
+		return nil
+	}
+
+	err = confirm(ctx, fmt.Sprintf("Delete %d releases", len(doomed)))
 
 	if err != nil {
 		return err
 	}
 
-	if debug {
-		fmt.Printf("Updated release (id: %d)!\n", *releaseId)
-		fmt.Println("Preparing to upload any assets")
-	}
+	// Keep going after a failure so one stuck release doesn't hold up the
+	// rest
+	failed := 0
 
-	for _, assetPath := range assets {
-		filename := path.Base(assetPath)
-		file, err := os.Open(assetPath)
+	for _, release := range doomed {
+		tagName := stringValue(release.TagName)
+		err := repo.DeleteRelease(netCtx, intValue(release.ID), gitHubToken)
 
-		if err != nil {
-			fmt.Printf("Failed to open %s. Skipping.\n", assetPath)
-			continue
+		if err == nil && deleteTags {
+			err = repo.DeleteTagRef(netCtx, tagName, gitHubToken)
 		}
 
-		if debug {
-			fmt.Printf("Uploading asset at %s as %s\n", assetPath, filename)
+		if err != nil {
+			fmt.Printf("Failed to delete %s: %v\n", tagName, err)
+			failed++
+			continue
 		}
 
-		err = repo.UploadReleaseAsset(netCtx, *releaseId, file, filename, gitHubToken)
+		fmt.Printf("Deleted %s\n", tagName)
+	}
 
-		if err != nil {
-			fmt.Printf("Error while uploading asset at %s\n", filename)
-			fmt.Println(err)
-		}
+	if failed > 0 {
+		return &pruneFailedError{failed: failed, total: len(doomed)}
 	}
 
 	return nil
 }
 
-func cmdUploadArtifacts(ctx *cli.Context) error {
-	dry := ctx.GlobalBool("dry-run")
-	debug := ctx.GlobalBool("debug")
-
-	if debug {
-		fmt.Println("Preparing to upload assets")
-	}
-
+func cmdShowRelease(ctx *cli.Context) error {
 	repoName := ctx.String("repository")
 	repoOwner := ctx.String("owner")
 	tagName := ctx.String("tag")
 
-	assetGlobPattern := ctx.String("glob-pattern")
-
 	gitHubToken := ctx.String("github-token")
 
-	repo := &gitHubRepo{
-		Name:  repoName,
-		Owner: repoOwner,
-	}
-
-	assets, err := findFiles(assetGlobPattern)
+	format, err := parseOutputFormat(ctx.String("output"), "text", "json")
 
 	if err != nil {
 		return err
 	}
 
-	if debug {
-		fmt.Println("Asset Upload Settings")
-		fmt.Println("=====================")
-		printRepoDebugStatements(repo)
-		fmt.Printf("Tag:\t\t\t%s\n", tagName)
-		printAssetDebugStatements(assets)
-	}
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
 
-	if dry {
-		fmt.Println("Dry run specified. Exiting.")
-		return nil
+	if err != nil {
+		return err
 	}
 
 	netCtx := context.Background()
 
-	releaseId, err := repo.GetReleaseIdByTag(netCtx, tagName, gitHubToken)
+	var release *gitHubRelease
+
+	if ctx.Bool("latest") {
+		release, err = repo.FindLatestRelease(netCtx, gitHubToken)
+	} else {
+		_, release, err = repo.FindReleaseByTag(netCtx, tagName, gitHubToken)
+	}
 
 	if err != nil {
 		return err
 	}
 
-	for _, assetPath := range assets {
-		filename := path.Base(assetPath)
-		file, err := os.Open(assetPath)
+	if release == nil {
+		return &releaseNotFoundError{tag: tagName, latest: ctx.Bool("latest")}
+	}
 
-		if err != nil {
-			fmt.Printf("Failed to open %s. Skipping.\n", assetPath)
-			continue
-		}
+	summary := newReleaseSummary(release)
 
-		if debug {
-			fmt.Printf("Uploading asset at %s as %s\n", assetPath, filename)
-		}
+	switch format.Name {
+	case "json":
+		return writeJSON(os.Stdout, summary)
+	case "template":
+		return writeTemplate(os.Stdout, format, summary)
+	}
 
-		err = repo.UploadReleaseAsset(netCtx, *releaseId, file, filename, gitHubToken)
+	writeReleaseSummary(os.Stdout, summary)
 
-		if err != nil {
-			fmt.Printf("Error while uploading asset at %s\n", filename)
-			fmt.Println(err)
-		}
+	return nil
+}
+
+func cmdNextVersion(ctx *cli.Context) error {
+	debug := ctx.GlobalBool("debug")
+
+	repoName := ctx.String("repository")
+	repoOwner := ctx.String("owner")
+
+	gitHubToken := ctx.String("github-token")
+
+	repo, err := newGitHubRepo(ctx, repoOwner, repoName)
+
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		printRepoDebugStatements(repo)
+	}
+
+	next, err := calculateNextVersion(context.Background(), repo, ctx.String("head"), ctx.String("pre-release-suffix"), gitHubToken, debug)
+
+	if err != nil {
+		return err
 	}
 
+	fmt.Println(next)
+
 	return nil
 }
 
@@ -731,7 +2441,13 @@ func cmdListFiles(ctx *cli.Context) error {
 }
 
 func printRepoDebugStatements(repo *gitHubRepo) {
-	fmt.Printf("Repo:\t\t\thttps://github.com/%s/%s\n", repo.Owner, repo.Name)
+	if repo.BaseURL == nil {
+		fmt.Printf("Repo:\t\t\thttps://github.com/%s/%s\n", repo.Owner, repo.Name)
+	} else {
+		fmt.Printf("Repo:\t\t\t%s/%s\n", repo.Owner, repo.Name)
+		fmt.Printf("GitHub API URL:\t\t%s\n", repo.BaseURL)
+		fmt.Printf("GitHub Upload URL:\t%s\n", repo.UploadURL)
+	}
 }
 
 func printReleaseDebugStatements(release *gitHubRelease) {
@@ -739,12 +2455,39 @@ func printReleaseDebugStatements(release *gitHubRelease) {
 	if release.TargetCommitish != nil {
 		fmt.Printf("Tag Commit:\t\t%s\n", *release.TargetCommitish)
 	}
-	fmt.Printf("Release Name/Title:\t%s\n", *release.Name)
-	fmt.Printf("Draft:\t\t\t%t\n", *release.Draft)
-	fmt.Printf("Pre-release:\t\t%t\n", *release.PreRelease)
-	fmt.Println("-----Begin Release Notes-----")
-	fmt.Printf("%s\n", *release.Body)
-	fmt.Println("------End Release Notes------")
+	if release.Name != nil {
+		fmt.Printf("Release Name/Title:\t%s\n", *release.Name)
+	} else {
+		fmt.Println("Release Name/Title:\t(unchanged)")
+	}
+	if release.Draft != nil {
+		fmt.Printf("Draft:\t\t\t%t\n", *release.Draft)
+	} else {
+		fmt.Println("Draft:\t\t\t(unchanged)")
+	}
+	if release.PreRelease != nil {
+		fmt.Printf("Pre-release:\t\t%t\n", *release.PreRelease)
+	} else {
+		fmt.Println("Pre-release:\t\t(unchanged)")
+	}
+	if release.Body != nil {
+		fmt.Println("-----Begin Release Notes-----")
+		fmt.Printf("%s\n", *release.Body)
+		fmt.Println("------End Release Notes------")
+	} else {
+		fmt.Println("Release Notes:\t\t(unchanged)")
+	}
+}
+
+func printAnnotatedTagDebugStatements(options *annotatedTagOptions) {
+	if options.Tagger != nil {
+		fmt.Printf("Tagger:\t\t\t%s <%s>\n", *options.Tagger.Name, *options.Tagger.Email)
+	} else {
+		fmt.Println("Tagger:\t\t\t(owner of the GitHub token)")
+	}
+	fmt.Println("-----Begin Tag Message-----")
+	fmt.Printf("%s\n", options.Message)
+	fmt.Println("------End Tag Message------")
 }
 
 func printAssetDebugStatements(assets []string) {
@@ -759,6 +2502,110 @@ func printAssetDebugStatements(assets []string) {
 	}
 }
 
+// newGitHubRepo sets up the repository along with the settings from the
+// flags that control how the GitHub API is called
+func newGitHubRepo(ctx *cli.Context, owner string, name string) (*gitHubRepo, error) {
+	retry := &retryPolicy{
+		MaxRetries: ctx.GlobalInt("max-retries"),
+		Timeout:    ctx.GlobalDuration("retry-timeout"),
+	}
+
+	repo := &gitHubRepo{
+		Owner: owner,
+		Name:  name,
+		Retry: retry,
+	}
+
+	baseURL, uploadURL, err := gitHubURLs(ctx.String("github-api-url"), ctx.String("github-upload-url"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	repo.BaseURL = baseURL
+	repo.UploadURL = uploadURL
+
+	return repo, nil
+}
+
+// gitHubURLs parses the API and upload URLs. Both are nil when the public
+// GitHub endpoints are used, which includes an API URL of
+// https://api.github.com/ on its own since GitHub Actions always sets
+// GITHUB_API_URL. Otherwise the upload URL is derived from the API URL when
+// it isn't given, which only works for the GitHub Enterprise Server layout.
+func gitHubURLs(apiURL string, uploadURLValue string) (*url.URL, *url.URL, error) {
+	baseURL, err := parseGitHubURL("--github-api-url", apiURL)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uploadURL, err := parseGitHubURL("--github-upload-url", uploadURLValue)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if uploadURL == nil && baseURL != nil && baseURL.Host == publicGitHubAPIHost {
+		return nil, nil, nil
+	}
+
+	if baseURL != nil && uploadURL == nil {
+		uploadURL = enterpriseUploadURL(baseURL)
+
+		if uploadURL == nil {
+			return nil, nil, &missingRequiredArgumentError{argument: "--github-upload-url"}
+		}
+	}
+
+	if uploadURL != nil && baseURL == nil {
+		return nil, nil, &missingRequiredArgumentError{argument: "--github-api-url"}
+	}
+
+	return baseURL, uploadURL, nil
+}
+
+// parseGitHubURL checks that value is an absolute HTTP(S) URL. GitHub API
+// URLs are resolved relative to these, so a trailing slash is added if it is
+// missing. An empty value means the public GitHub endpoints are used and
+// results in a nil URL.
+func parseGitHubURL(argument string, value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return nil, &badArgumentError{argument: argument, reason: err.Error()}
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		reason := fmt.Sprintf("expected an absolute http or https URL but found \"%s\"", value)
+		return nil, &badArgumentError{argument: argument, reason: reason}
+	}
+
+	if !strings.HasSuffix(parsed.Path, "/") {
+		parsed.Path += "/"
+	}
+
+	return parsed, nil
+}
+
+// enterpriseUploadURL guesses the upload URL from the API URL using the
+// layout GitHub Enterprise Server uses: uploads live under /api/uploads/
+// next to the /api/v3/ API. It returns nil for any other API URL.
+func enterpriseUploadURL(baseURL *url.URL) *url.URL {
+	if !strings.HasSuffix(baseURL.Path, "/api/v3/") {
+		return nil
+	}
+
+	uploadURL := *baseURL
+	uploadURL.Path = strings.TrimSuffix(uploadURL.Path, "v3/") + "uploads/"
+
+	return &uploadURL
+}
+
 func splitRepositoryName(name string) (owner string, repo string, e error) {
 	endOwnerIndex := strings.Index(name, "/")
 
@@ -786,6 +2633,69 @@ func splitRepositoryName(name string) (owner string, repo string, e error) {
 	return
 }
 
+// repoPositionalArguments validates the positional arguments of commands
+// whose first argument is REPO. REPO can be left out when the configuration
+// file names the repository.
+func repoPositionalArguments(ctx *cli.Context, expected int) (cli.Args, error) {
+	arguments := ctx.Args()
+
+	if len(arguments) == expected-1 {
+		repo, err := configuredRepository(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if repo != "" {
+			arguments = append(cli.Args{repo}, arguments...)
+		}
+	}
+
+	if len(arguments) != expected {
+		return nil, &incorrectArgumentNumberError{expected: expected, received: len(arguments)}
+	}
+
+	return arguments, nil
+}
+
+// commitishPositionalArguments validates the positional arguments of
+// commands whose first argument is REPO and whose last is COMMITISH, which
+// can be left out, in which case the command uses the repository's default
+// branch. With a repository in the configuration file, one argument short
+// could mean either was left out. A first argument of the form owner/repo is
+// then only taken as REPO when it is the configured repository, and any
+// other is refused since branches and tags can contain a / too.
+func commitishPositionalArguments(ctx *cli.Context, expected int) (cli.Args, error) {
+	arguments := ctx.Args()
+
+	if len(arguments) == expected-1 && strings.Contains(arguments.First(), "/") {
+		repo, err := configuredRepository(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if repo != "" && arguments.First() == repo {
+			return arguments, nil
+		}
+
+		if repo != "" {
+			reason := fmt.Sprintf("\"%s\" could be the repository, or a tag or branch of %s from the configuration file; pass every positional argument to tell them apart", arguments.First(), repo)
+			return nil, &badArgumentError{argument: "REPO", reason: reason}
+		}
+	}
+
+	arguments, err := repoPositionalArguments(ctx, expected)
+
+	if _, ok := err.(*incorrectArgumentNumberError); ok {
+		if withoutCommitish, shorterErr := repoPositionalArguments(ctx, expected-1); shorterErr == nil {
+			return withoutCommitish, nil
+		}
+	}
+
+	return arguments, err
+}
+
 func validatePositionalArgumentCount(ctx *cli.Context, expected int) error {
 	received := ctx.NArg()
 
@@ -796,6 +2706,27 @@ func validatePositionalArgumentCount(ctx *cli.Context, expected int) error {
 	return nil
 }
 
+// toggleFlagValue resolves a pair of boolean flags like --draft/--no-draft
+// into a single value. It returns nil when neither flag was passed so the
+// field can be left untouched.
+func toggleFlagValue(ctx *cli.Context, on string, off string) (*bool, error) {
+	onSet := ctx.IsSet(on) && ctx.Bool(on)
+	offSet := ctx.IsSet(off) && ctx.Bool(off)
+
+	if onSet && offSet {
+		reason := fmt.Sprintf("--%s and --%s cannot be used together", on, off)
+		return nil, &badArgumentError{argument: "--" + on, reason: reason}
+	}
+
+	if !onSet && !offSet {
+		return nil, nil
+	}
+
+	value := onSet
+
+	return &value, nil
+}
+
 func validateGitHubToken(gitHubToken string) error {
 	if gitHubToken == "" {
 		return &missingRequiredArgumentError{argument: "--github-token"}
@@ -819,6 +2750,19 @@ func (e *badArgumentError) Error() string {
 	return message
 }
 
+func (e *releaseNotFoundError) Error() string {
+	if e.latest {
+		return "There is no latest release; drafts and pre-releases are never the latest release"
+	}
+
+	if e.checkedTag {
+		return fmt.Sprintf("There is no release or tag for %s", e.tag)
+	}
+
+	message := fmt.Sprintf("There is no release for tag %s", e.tag)
+	return message
+}
+
 func (e *missingRequiredArgumentError) ExitCode() int {
 	return 64
 }
@@ -831,6 +2775,10 @@ func (e *badArgumentError) ExitCode() int {
 	return 64
 }
 
+func (e *releaseNotFoundError) ExitCode() int {
+	return 1
+}
+
 func (e *badGlobPatternError) ExitCode() int {
 	return 64
 }
@@ -1,6 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"gopkg.in/urfave/cli.v1"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -22,3 +34,575 @@ func TestSplitRepositoryName(test *testing.T) {
 		test.Fatalf("Expected repo to be %s but got %s", expectedRepo, repo)
 	}
 }
+
+// fakeGitHub is a GitHub API that keeps releases in memory, enough to run
+// the release commands against. Like GitHub, it never returns draft releases
+// when they are looked up by tag.
+type fakeGitHub struct {
+	lock     sync.Mutex
+	releases []map[string]interface{}
+	// tags maps the names of the tags that exist to the SHA of their commit
+	tags map[string]string
+	// annotated are the tags that point at a tag object
+	annotated map[string]bool
+	// moved are the tags that were moved to another commit
+	moved []string
+	// comparisons are the base...head of every comparison asked for
+	comparisons []string
+	// edits are the bodies of every release update
+	edits  []map[string]interface{}
+	nextId int
+}
+
+// redirectTransport sends every request to the test server instead of
+// GitHub
+type redirectTransport struct {
+	server *url.URL
+	base   http.RoundTripper
+}
+
+func newFakeGitHub() *fakeGitHub {
+	return &fakeGitHub{tags: map[string]string{}, annotated: map[string]bool{}, nextId: 1}
+}
+
+func (fake *fakeGitHub) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	route := request.Method + " " + strings.TrimPrefix(request.URL.Path, "/repos/timberio/grease")
+
+	switch {
+	case route == "GET ":
+		fake.write(writer, http.StatusOK, map[string]interface{}{"default_branch": "main"})
+	case strings.HasPrefix(route, "GET /commits/"):
+		if strings.Contains(request.Header.Get("Accept"), "sha") {
+			writer.Write([]byte("566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"))
+			return
+		}
+
+		fake.write(writer, http.StatusOK, map[string]interface{}{
+			"sha":    "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2",
+			"commit": map[string]interface{}{"message": "Fix asset names"},
+		})
+	case strings.HasPrefix(route, "GET /git/refs/tags/"):
+		tag := strings.TrimPrefix(route, "GET /git/refs/tags/")
+		sha, ok := fake.tags[tag]
+
+		if !ok {
+			fake.write(writer, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+			return
+		}
+
+		object := map[string]interface{}{"sha": sha, "type": "commit"}
+
+		if fake.annotated[tag] {
+			object = map[string]interface{}{"sha": "b7e3d2c1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5", "type": "tag"}
+		}
+
+		fake.write(writer, http.StatusOK, map[string]interface{}{"ref": "refs/tags/" + tag, "object": object})
+	case strings.HasPrefix(route, "PATCH /git/refs/tags/"):
+		tag := strings.TrimPrefix(route, "PATCH /git/refs/tags/")
+		fake.moved = append(fake.moved, tag)
+		fake.write(writer, http.StatusOK, map[string]interface{}{"ref": "refs/tags/" + tag})
+	case strings.HasPrefix(route, "GET /compare/"):
+		fake.comparisons = append(fake.comparisons, strings.TrimPrefix(route, "GET /compare/"))
+		fake.write(writer, http.StatusOK, map[string]interface{}{"total_commits": 0, "commits": []interface{}{}})
+	case route == "POST /git/refs":
+		fake.write(writer, http.StatusCreated, map[string]interface{}{"ref": "refs/tags/nightly"})
+	case strings.HasPrefix(route, "GET /releases/tags/"):
+		for _, release := range fake.releases {
+			if release["tag_name"] == strings.TrimPrefix(route, "GET /releases/tags/") && release["draft"] != true {
+				fake.write(writer, http.StatusOK, release)
+				return
+			}
+		}
+
+		fake.write(writer, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+	case route == "GET /releases":
+		fake.write(writer, http.StatusOK, fake.releases)
+	case route == "POST /releases":
+		release := map[string]interface{}{}
+		json.NewDecoder(request.Body).Decode(&release)
+		release["id"] = fake.nextId
+		fake.nextId++
+		fake.releases = append(fake.releases, release)
+		fake.write(writer, http.StatusCreated, release)
+	case strings.HasPrefix(route, "PATCH /releases/"):
+		release := fake.release(route)
+
+		if release == nil {
+			fake.write(writer, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+			return
+		}
+
+		edit := map[string]interface{}{}
+		json.NewDecoder(request.Body).Decode(&edit)
+		fake.edits = append(fake.edits, edit)
+
+		for key, value := range edit {
+			release[key] = value
+		}
+
+		fake.write(writer, http.StatusOK, release)
+	case strings.HasPrefix(route, "DELETE /releases/"):
+		deleted := fake.release(route)
+
+		if deleted == nil {
+			fake.write(writer, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+			return
+		}
+
+		var kept []map[string]interface{}
+
+		for _, release := range fake.releases {
+			if release["id"] != deleted["id"] {
+				kept = append(kept, release)
+			}
+		}
+
+		fake.releases = kept
+		writer.WriteHeader(http.StatusNoContent)
+	case strings.HasSuffix(route, "/assets"):
+		fake.write(writer, http.StatusOK, []interface{}{})
+	default:
+		// Anything else doesn't exist
+		fake.write(writer, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+	}
+}
+
+// release finds the release whose id ends the path of route
+func (fake *fakeGitHub) release(route string) map[string]interface{} {
+	id, _ := strconv.Atoi(route[strings.LastIndex(route, "/")+1:])
+
+	for _, release := range fake.releases {
+		if release["id"] == id {
+			return release
+		}
+	}
+
+	return nil
+}
+
+func (fake *fakeGitHub) write(writer http.ResponseWriter, status int, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(body)
+}
+
+func (transport *redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	redirected := *request
+	redirected.URL = &url.URL{}
+	*redirected.URL = *request.URL
+	redirected.URL.Scheme = transport.server.Scheme
+	redirected.URL.Host = transport.server.Host
+	redirected.Host = ""
+
+	return transport.base.RoundTrip(&redirected)
+}
+
+// redirectGitHub points the GitHub client at server until the returned
+// function is called
+func redirectGitHub(server *httptest.Server) func() {
+	serverURL, _ := url.Parse(server.URL)
+	transport := http.DefaultTransport
+	http.DefaultTransport = &redirectTransport{server: serverURL, base: transport}
+
+	return func() {
+		http.DefaultTransport = transport
+	}
+}
+
+// runCommand runs grease against the server with the given command line
+// arguments, returning what it printed and its exit status
+func runCommand(test *testing.T, server *httptest.Server, arguments ...string) (string, int) {
+	status := 0
+
+	exiter := cli.OsExiter
+	errWriter := cli.ErrWriter
+	stdout := os.Stdout
+
+	defer func() {
+		cli.OsExiter = exiter
+		cli.ErrWriter = errWriter
+		os.Stdout = stdout
+	}()
+
+	defer redirectGitHub(server)()
+
+	cli.OsExiter = func(code int) {
+		status = code
+	}
+
+	var errors bytes.Buffer
+	cli.ErrWriter = &errors
+
+	reader, writer, err := os.Pipe()
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	os.Stdout = writer
+
+	output := make(chan string)
+
+	go func() {
+		contents, _ := ioutil.ReadAll(reader)
+		output <- string(contents)
+	}()
+
+	command := append([]string{"grease", "--max-retries", "0", arguments[0], "--github-token", "token"}, arguments[1:]...)
+
+	err = newApp().Run(command)
+
+	writer.Close()
+	printed := <-output + errors.String()
+
+	if err != nil && status == 0 {
+		test.Fatalf("Did not expect to receive error: %v\n%s", err, printed)
+	}
+
+	return printed, status
+}
+
+func TestEnsureReleaseDraftTwice(test *testing.T) {
+	fake := newFakeGitHub()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		output, status := runCommand(test, server, "ensure-release", "--draft", "--name", "Grease v1.1.0", "timberio/grease", "v1.1.0", "main")
+
+		if status != 0 {
+			test.Fatalf("Expected ensure-release to succeed but it exited with %d:\n%s", status, output)
+		}
+	}
+
+	if len(fake.releases) != 1 {
+		test.Fatalf("Expected a single release but found %d", len(fake.releases))
+	}
+
+	if fake.releases[0]["draft"] != true {
+		test.Fatalf("Expected the release to still be a draft")
+	}
+}
+
+func TestEnsureReleaseGeneratesNotesUpToTag(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{
+		{"id": 2, "tag_name": "v1.1.0", "body": "Old notes"},
+		{"id": 1, "tag_name": "v1.0.0"},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "ensure-release", "--generate-notes", "timberio/grease", "v1.1.0", "main")
+
+	if status != 0 {
+		test.Fatalf("Expected ensure-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.comparisons) != 1 || fake.comparisons[0] != "v1.0.0...v1.1.0" {
+		test.Fatalf("Expected the notes to compare v1.0.0 with the tag but got %v", fake.comparisons)
+	}
+}
+
+func TestDeleteReleaseDraft(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "delete-release", "--yes", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected delete-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.releases) != 0 {
+		test.Fatalf("Expected the draft release to be deleted")
+	}
+}
+
+func TestShowReleaseDraft(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "show-release", "--output", "json", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected show-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	var summary struct {
+		TagName string `json:"tag_name"`
+		Draft   bool   `json:"draft"`
+	}
+
+	err := json.Unmarshal([]byte(output), &summary)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v\n%s", err, output)
+	}
+
+	if summary.TagName != "v1.1.0" || !summary.Draft {
+		test.Fatalf("Expected the draft release for v1.1.0 but got %s", output)
+	}
+}
+
+func TestRollingReleaseDraft(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "nightly", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "rolling-release", "timberio/grease", "nightly", "main")
+
+	if status != 0 {
+		test.Fatalf("Expected rolling-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.releases) != 1 {
+		test.Fatalf("Expected the draft release to be updated rather than a second release created")
+	}
+
+	if body, _ := fake.releases[0]["body"].(string); !strings.Contains(body, "566e0e8") {
+		test.Fatalf("Expected the notes to describe the new commit but got %q", body)
+	}
+}
+
+func TestRollingReleaseAnnotatedTagUpToDate(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "nightly"}}
+	fake.tags["nightly"] = "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	fake.annotated["nightly"] = true
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "rolling-release", "timberio/grease", "nightly", "main")
+
+	if status != 0 {
+		test.Fatalf("Expected rolling-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.moved) != 0 {
+		test.Fatalf("Did not expect an annotated tag already at the commit to be moved")
+	}
+}
+
+func TestUpdateReleaseDraftForPushedTag(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "draft": true}}
+	fake.tags["v1.1.0"] = "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "update-release", "--name", "Grease v1.1.0", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected update-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.releases) != 1 {
+		test.Fatalf("Expected the draft release to be updated rather than a second release created")
+	}
+
+	if fake.releases[0]["name"] != "Grease v1.1.0" || fake.releases[0]["draft"] != true {
+		test.Fatalf("Expected the draft release to be renamed but got %v", fake.releases[0])
+	}
+}
+
+func TestUpdateReleaseForPushedTag(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.tags["v1.1.0"] = "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "update-release", "--name", "Grease v1.1.0", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected update-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if !strings.Contains(output, "for existing tag v1.1.0 at 566e0e8") || len(fake.releases) != 1 {
+		test.Fatalf("Expected a release to be created from the tag but got:\n%s", output)
+	}
+}
+
+func TestUpdateReleaseAssetsOnly(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "name": "Grease v1.1.0", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "update-release", "--assets", "no-such-asset-*", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected update-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.edits) != 1 {
+		test.Fatalf("Expected the release to be updated once but got %v", fake.edits)
+	}
+
+	for _, edit := range fake.edits {
+		for _, field := range []string{"name", "body", "draft", "prerelease"} {
+			if _, ok := edit[field]; ok {
+				test.Fatalf("Expected update-release --assets to leave %s alone but sent %v", field, edit)
+			}
+		}
+	}
+
+	if fake.releases[0]["name"] != "Grease v1.1.0" || fake.releases[0]["draft"] != true {
+		test.Fatalf("Expected the release to be unchanged but got %v", fake.releases[0])
+	}
+}
+
+func TestUpdateReleaseNoDraft(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "update-release", "--no-draft", "timberio/grease", "v1.1.0")
+
+	if status != 0 {
+		test.Fatalf("Expected update-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.edits) != 1 || fake.edits[0]["draft"] != false {
+		test.Fatalf("Expected update-release --no-draft to send draft:false but sent %v", fake.edits)
+	}
+}
+
+func TestUpdateReleaseDraftAndNoDraft(test *testing.T) {
+	fake := newFakeGitHub()
+	fake.releases = []map[string]interface{}{{"id": 7, "tag_name": "v1.1.0", "draft": true}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "update-release", "--draft", "--no-draft", "timberio/grease", "v1.1.0")
+
+	if status != 64 {
+		test.Fatalf("Expected --draft with --no-draft to exit with 64 but got %d:\n%s", status, output)
+	}
+
+	if len(fake.edits) != 0 {
+		test.Fatalf("Expected the release to be left alone but sent %v", fake.edits)
+	}
+}
+
+func TestCreateReleaseWithConfiguredRepo(test *testing.T) {
+	dir, err := ioutil.TempDir("", "grease")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, defaultConfigFileName)
+	err = ioutil.WriteFile(path, []byte("repo: timberio/grease\n"), 0644)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	os.Setenv("GREASE_CONFIG", path)
+	defer os.Unsetenv("GREASE_CONFIG")
+
+	fake := newFakeGitHub()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	output, status := runCommand(test, server, "create-release", "timberio/grease", "v1.0.0")
+
+	if status != 0 {
+		test.Fatalf("Expected create-release to succeed but it exited with %d:\n%s", status, output)
+	}
+
+	if len(fake.releases) != 1 || fake.releases[0]["tag_name"] != "v1.0.0" {
+		test.Fatalf("Expected a release for v1.0.0 but got %v", fake.releases)
+	}
+
+	output, status = runCommand(test, server, "create-release", "timberio/other", "v1.1.0")
+
+	if status != 64 {
+		test.Fatalf("Expected an ambiguous REPO to exit with 64 but got %d:\n%s", status, output)
+	}
+
+	if len(fake.releases) != 1 {
+		test.Fatalf("Did not expect a release to be created for an ambiguous REPO")
+	}
+}
+
+func TestParseGitHubURLAddsTrailingSlash(test *testing.T) {
+	expected := "https://github.example.com/api/v3/"
+
+	parsed, err := parseGitHubURL("--github-api-url", "https://github.example.com/api/v3")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if parsed.String() != expected {
+		test.Fatalf("Expected URL to be %s but got %s", expected, parsed)
+	}
+
+	uploadURL := enterpriseUploadURL(parsed)
+	expectedUpload := "https://github.example.com/api/uploads/"
+
+	if uploadURL.String() != expectedUpload {
+		test.Fatalf("Expected upload URL to be %s but got %s", expectedUpload, uploadURL)
+	}
+}
+
+func TestParseGitHubURLRejectsRelativeURL(test *testing.T) {
+	_, err := parseGitHubURL("--github-api-url", "github.example.com/api/v3/")
+
+	if err == nil {
+		test.Fatalf("Expected an error for a URL without a scheme")
+	}
+}
+
+func TestGitHubURLsPublicAPI(test *testing.T) {
+	baseURL, uploadURL, err := gitHubURLs("https://api.github.com", "")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if baseURL != nil || uploadURL != nil {
+		test.Fatalf("Expected the public GitHub endpoints but got %s and %s", baseURL, uploadURL)
+	}
+}
+
+func TestGitHubURLsRequiresUploadURL(test *testing.T) {
+	_, _, err := gitHubURLs("https://github.example.com/", "")
+
+	if _, ok := err.(*missingRequiredArgumentError); !ok {
+		test.Fatalf("Expected a missingRequiredArgumentError but got %v", err)
+	}
+
+	baseURL, uploadURL, err := gitHubURLs("https://github.example.com/", "https://uploads.example.com/")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if baseURL.String() != "https://github.example.com/" || uploadURL.String() != "https://uploads.example.com/" {
+		test.Fatalf("Expected the URLs as passed but got %s and %s", baseURL, uploadURL)
+	}
+}
+
+func TestReleaseNotFoundErrorLatest(test *testing.T) {
+	expected := "There is no latest release; drafts and pre-releases are never the latest release"
+
+	err := &releaseNotFoundError{latest: true}
+
+	if err.Error() != expected {
+		test.Fatalf("Expected %q but got %q", expected, err.Error())
+	}
+}
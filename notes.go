@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxReleaseNotesLength is the most characters GitHub accepts in the body of
+// a release
+const maxReleaseNotesLength = 125000
+
+var utf8ByteOrderMark = []byte("\xef\xbb\xbf")
+
+// notesFlags are the flags that set the release notes; at most one of them
+// can be passed
+var notesFlags = []string{"notes", "notes-file", "notes-from-changelog", "generate-notes"}
+
+// releaseNotes returns the release notes from whichever of the notes flags
+// was passed, or nil if none of them were so the notes can be left alone.
+// Notes from --notes and --notes-file are rendered as templates when
+// templateCtx isn't nil.
+func releaseNotes(ctx *cli.Context, repo *gitHubRepo, templateCtx *templateContext) (*string, error) {
+	var passed []string
+
+	for _, name := range notesFlags {
+		if ctx.IsSet(name) {
+			passed = append(passed, "--"+name)
+		}
+	}
+
+	if len(passed) > 1 {
+		reason := fmt.Sprintf("only one of %s can be used at a time", strings.Join(passed, ", "))
+		return nil, &badArgumentError{argument: passed[0], reason: reason}
+	}
+
+	for _, name := range []string{"previous-tag", "generate-notes-from"} {
+		if ctx.IsSet(name) && !ctx.Bool("generate-notes") {
+			return nil, &badArgumentError{argument: "--" + name, reason: "can only be used with --generate-notes"}
+		}
+	}
+
+	if ctx.Bool("generate-notes") {
+		options, err := newGeneratedNotesOptions(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		notes, err := generateReleaseNotes(context.Background(), repo, options, ctx.String("github-token"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &notes, nil
+	}
+
+	if ctx.IsSet("notes-from-changelog") {
+		notes, err := readChangelogNotes(ctx.String("notes-from-changelog"), ctx.String("tag"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = validateReleaseNotes("--notes-from-changelog", notes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &notes, nil
+	}
+
+	if ctx.IsSet("notes-file") {
+		notes, err := readNotesFile(ctx.String("notes-file"), os.Stdin)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return renderNotesTemplate("--notes-file", notes, templateCtx)
+	}
+
+	if ctx.IsSet("notes") {
+		notes := ctx.String("notes")
+
+		err := validateReleaseNotes("--notes", notes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return renderNotesTemplate("--notes", notes, templateCtx)
+	}
+
+	return nil, nil
+}
+
+// renderNotesTemplate renders the notes and checks they are still within
+// GitHub's limits afterwards
+func renderNotesTemplate(argument string, notes string, templateCtx *templateContext) (*string, error) {
+	if templateCtx == nil {
+		return &notes, nil
+	}
+
+	rendered, err := renderTemplate(argument, notes, templateCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateReleaseNotes(argument, rendered)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rendered, nil
+}
+
+// readNotesFile reads release notes from the file at path, or from stdin
+// when path is "-"
+func readNotesFile(path string, stdin io.Reader) (string, error) {
+	var contents []byte
+	var err error
+
+	if path == "-" {
+		contents, err = ioutil.ReadAll(stdin)
+	} else {
+		contents, err = ioutil.ReadFile(path)
+	}
+
+	if err != nil {
+		return "", &badArgumentError{argument: "--notes-file", reason: err.Error()}
+	}
+
+	// Editors on Windows like to start UTF-8 files with a byte order mark,
+	// which GitHub would show as part of the notes
+	contents = bytes.TrimPrefix(contents, utf8ByteOrderMark)
+	notes := string(contents)
+
+	err = validateReleaseNotes("--notes-file", notes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return notes, nil
+}
+
+func validateReleaseNotes(argument string, notes string) error {
+	if !utf8.ValidString(notes) {
+		return &badArgumentError{argument: argument, reason: "release notes must be valid UTF-8"}
+	}
+
+	length := utf8.RuneCountInString(notes)
+
+	if length > maxReleaseNotesLength {
+		reason := fmt.Sprintf("release notes are %d characters long but GitHub allows at most %d", length, maxReleaseNotesLength)
+		return &badArgumentError{argument: argument, reason: reason}
+	}
+
+	return nil
+}
+
+// generatedNotesOptions controls what generateReleaseNotes compares and how
+// it renders the result
+type generatedNotesOptions struct {
+	// Tag is the tag of the release the notes are for
+	Tag string
+	// Head is the commit-ish the release points at
+	Head string
+	// Base is the tag to compare with; the previous release when empty
+	Base string
+	// From is either "commits" or "pull-requests"
+	From string
+	// Sections group pull requests by label when From is "pull-requests"
+	Sections []notesSection
+}
+
+func newGeneratedNotesOptions(ctx *cli.Context) (*generatedNotesOptions, error) {
+	options := &generatedNotesOptions{
+		Tag:  ctx.String("tag"),
+		Head: ctx.String("target-commitish"),
+		Base: ctx.String("previous-tag"),
+		From: ctx.String("generate-notes-from"),
+	}
+
+	if options.Head == "" {
+		options.Head = options.Tag
+	}
+
+	switch options.From {
+	case "commits":
+	case "pull-requests":
+		sections, err := configuredNotesSections(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		options.Sections = sections
+	default:
+		reason := fmt.Sprintf("expected commits or pull-requests but found \"%s\"", options.From)
+		return nil, &badArgumentError{argument: "--generate-notes-from", reason: reason}
+	}
+
+	return options, nil
+}
+
+// generateReleaseNotes lists the commits or the pull requests merged between
+// the previous release and the head of the release. The previous release is
+// the newest published release other than the one for the tag, unless a base
+// is given explicitly.
+func generateReleaseNotes(netCtx context.Context, repo *gitHubRepo, options *generatedNotesOptions, gitHubToken string) (string, error) {
+	base := options.Base
+
+	if base == "" {
+		previous, err := previousReleaseTag(netCtx, repo, options.Tag, gitHubToken)
+
+		if err != nil {
+			return "", err
+		}
+
+		base = previous
+	}
+
+	comparison, err := repo.CompareCommits(netCtx, base, options.Head, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	var notes string
+
+	if options.From == "pull-requests" {
+		pullRequests, err := mergedPullRequests(netCtx, repo, comparison, gitHubToken)
+
+		if err != nil {
+			return "", err
+		}
+
+		notes = renderPullRequestNotes(base, pullRequests, options.Sections)
+	} else {
+		notes = renderCommitNotes(base, comparison.Commits, comparison.TotalCommits)
+	}
+
+	err = validateReleaseNotes("--generate-notes", notes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return notes, nil
+}
+
+func previousReleaseTag(netCtx context.Context, repo *gitHubRepo, tag string, gitHubToken string) (string, error) {
+	releases, err := repo.ListReleases(netCtx, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, release := range releases {
+		if release.TagName == nil || *release.TagName == tag {
+			continue
+		}
+
+		if release.Draft != nil && *release.Draft {
+			continue
+		}
+
+		return *release.TagName, nil
+	}
+
+	return "", &badArgumentError{argument: "--generate-notes", reason: "no previous release to compare with was found; pass --previous-tag"}
+}
+
+// renderCommitNotes renders a Markdown list with the subject, short SHA and
+// author of each commit
+func renderCommitNotes(base string, commits []*gitHubCommit, total int) string {
+	var notes bytes.Buffer
+
+	fmt.Fprintf(&notes, "## Changes since %s\n\n", base)
+
+	if len(commits) == 0 {
+		notes.WriteString("No changes.\n")
+	}
+
+	for _, commit := range commits {
+		subject := ""
+
+		if commit.Message != nil {
+			subject = strings.TrimSpace(strings.SplitN(*commit.Message, "\n", 2)[0])
+		}
+
+		sha := shortSHA(stringValue(commit.SHA))
+
+		author := ""
+
+		if commit.AuthorLogin != nil {
+			author = "@" + *commit.AuthorLogin
+		} else if commit.AuthorName != nil {
+			author = *commit.AuthorName
+		}
+
+		fmt.Fprintf(&notes, "- %s (%s) by %s\n", subject, sha, author)
+	}
+
+	if total > len(commits) {
+		fmt.Fprintf(&notes, "\n...and %d more commits.\n", total-len(commits))
+	}
+
+	return notes.String()
+}
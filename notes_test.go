@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadNotesFileFromStdin(test *testing.T) {
+	stdin := strings.NewReader("\xef\xbb\xbf## Added\n\n- Café support\n")
+
+	notes, err := readNotesFile("-", stdin)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	expected := "## Added\n\n- Café support\n"
+
+	if notes != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, notes)
+	}
+}
+
+func TestValidateReleaseNotes(test *testing.T) {
+	err := validateReleaseNotes("--notes-file", "\xff\xfe")
+
+	if err == nil {
+		test.Fatalf("Expected an error for invalid UTF-8")
+	}
+
+	// Multi-byte characters count once towards the limit
+	err = validateReleaseNotes("--notes-file", strings.Repeat("é", maxReleaseNotesLength))
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	err = validateReleaseNotes("--notes-file", strings.Repeat("a", maxReleaseNotesLength+1))
+
+	if err == nil {
+		test.Fatalf("Expected an error for notes over the limit")
+	}
+}
+
+func TestRenderCommitNotes(test *testing.T) {
+	sha := "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	message := "Fix asset names\n\nAssets were uploaded with their full path."
+	login := "octocat"
+	otherSHA := "1234567890abcdef"
+	otherMessage := "Update README"
+	name := "Jane Doe"
+
+	commits := []*gitHubCommit{
+		{SHA: &sha, Message: &message, AuthorLogin: &login},
+		{SHA: &otherSHA, Message: &otherMessage, AuthorName: &name},
+	}
+
+	expected := "## Changes since v1.0.0\n\n" +
+		"- Fix asset names (566e0e8) by @octocat\n" +
+		"- Update README (1234567) by Jane Doe\n" +
+		"\n...and 3 more commits.\n"
+
+	notes := renderCommitNotes("v1.0.0", commits, 5)
+
+	if notes != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, notes)
+	}
+}
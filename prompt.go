@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"strings"
+)
+
+type notConfirmedError struct {
+	action string
+}
+
+// confirm asks the user to confirm a destructive action on stdin. Without a
+// terminal to ask on, the action has to be confirmed up front with --yes.
+func confirm(ctx *cli.Context, action string) error {
+	if ctx.Bool("yes") {
+		return nil
+	}
+
+	info, err := os.Stdin.Stat()
+
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return &missingRequiredArgumentError{argument: "--yes"}
+	}
+
+	return confirmFrom(os.Stdin, os.Stdout, action)
+}
+
+func confirmFrom(in io.Reader, out io.Writer, action string) error {
+	fmt.Fprintf(out, "%s? [y/N] ", action)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+
+	return &notConfirmedError{action: action}
+}
+
+func (e *notConfirmedError) Error() string {
+	message := fmt.Sprintf("Did not %s since it was not confirmed", strings.ToLower(e.action[:1])+e.action[1:])
+	return message
+}
+
+func (e *notConfirmedError) ExitCode() int {
+	return 1
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirmFrom(test *testing.T) {
+	for answer, confirmed := range map[string]bool{"y\n": true, "Yes\n": true, "n\n": false, "\n": false, "": false} {
+		var out bytes.Buffer
+
+		err := confirmFrom(strings.NewReader(answer), &out, "Delete these")
+
+		if confirmed && err != nil {
+			test.Fatalf("Did not expect to receive error: %v", err)
+		}
+
+		if !confirmed && err == nil {
+			test.Fatalf("Expected %q not to confirm", answer)
+		}
+
+		if out.String() != "Delete these? [y/N] " {
+			test.Fatalf("Expected a prompt but got %q", out.String())
+		}
+	}
+}
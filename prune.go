@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// retentionPolicy decides which releases prune-releases keeps. Releases are
+// kept unless a rule says otherwise, so a policy with no rules keeps
+// everything.
+type retentionPolicy struct {
+	// KeepStable is how many of the newest stable releases to keep, or -1
+	// to keep them all
+	KeepStable int
+	// KeepPreReleasesFor is how old a pre-release can get before it is
+	// deleted, or 0 to keep them all
+	KeepPreReleasesFor time.Duration
+	// Protect matches tags that are always kept
+	Protect *regexp.Regexp
+	Now     time.Time
+}
+
+type pruneDecision struct {
+	Release *gitHubRelease
+	Delete  bool
+	Reason  string
+}
+
+type pruneFailedError struct {
+	failed int
+	total  int
+}
+
+func newRetentionPolicy(ctx *cli.Context) (*retentionPolicy, error) {
+	policy := &retentionPolicy{
+		KeepStable:         -1,
+		KeepPreReleasesFor: ctx.Duration("keep-pre-releases-for"),
+		Now:                time.Now(),
+	}
+
+	if ctx.IsSet("keep-stable") {
+		policy.KeepStable = ctx.Int("keep-stable")
+
+		if policy.KeepStable < 0 {
+			reason := fmt.Sprintf("expected at least 0 but found %d", policy.KeepStable)
+			return nil, &badArgumentError{argument: "--keep-stable", reason: reason}
+		}
+	}
+
+	if policy.KeepPreReleasesFor < 0 {
+		reason := fmt.Sprintf("expected a positive duration but found %s", policy.KeepPreReleasesFor)
+		return nil, &badArgumentError{argument: "--keep-pre-releases-for", reason: reason}
+	}
+
+	if pattern := ctx.String("protect"); pattern != "" {
+		compiled, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, &badArgumentError{argument: "--protect", reason: err.Error()}
+		}
+
+		policy.Protect = compiled
+	}
+
+	return policy, nil
+}
+
+// planPrune applies the policy to the releases, newest first. Drafts are
+// always kept since they haven't been published yet.
+func planPrune(releases []*gitHubRelease, policy *retentionPolicy) []*pruneDecision {
+	sorted := make([]*gitHubRelease, len(releases))
+	copy(sorted, releases)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return createdAt(sorted[i]).After(createdAt(sorted[j]))
+	})
+
+	decisions := make([]*pruneDecision, len(sorted))
+	stable := 0
+
+	for i, release := range sorted {
+		decision := &pruneDecision{Release: release}
+		decisions[i] = decision
+
+		switch {
+		case policy.Protect != nil && policy.Protect.MatchString(stringValue(release.TagName)):
+			decision.Reason = "protected"
+		case boolValue(release.Draft):
+			decision.Reason = "draft"
+		case boolValue(release.PreRelease):
+			if policy.KeepPreReleasesFor == 0 {
+				decision.Reason = "pre-release"
+			} else if release.CreatedAt != nil && policy.Now.Sub(*release.CreatedAt) > policy.KeepPreReleasesFor {
+				decision.Delete = true
+				decision.Reason = fmt.Sprintf("pre-release older than %s", policy.KeepPreReleasesFor)
+			} else {
+				decision.Reason = fmt.Sprintf("pre-release newer than %s", policy.KeepPreReleasesFor)
+			}
+		default:
+			stable++
+
+			if policy.KeepStable < 0 {
+				decision.Reason = "stable release"
+			} else if stable > policy.KeepStable {
+				decision.Delete = true
+				decision.Reason = fmt.Sprintf("not one of the %d newest stable releases", policy.KeepStable)
+			} else {
+				decision.Reason = fmt.Sprintf("one of the %d newest stable releases", policy.KeepStable)
+			}
+		}
+	}
+
+	return decisions
+}
+
+func createdAt(release *gitHubRelease) time.Time {
+	if release.CreatedAt == nil {
+		return time.Time{}
+	}
+
+	return *release.CreatedAt
+}
+
+// writePrunePlan prints what is going to happen to every release
+func writePrunePlan(out io.Writer, decisions []*pruneDecision) {
+	writer := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "TAG\tCREATED\tACTION\tREASON")
+
+	for _, decision := range decisions {
+		action := "keep"
+
+		if decision.Delete {
+			action = "delete"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", stringValue(decision.Release.TagName), formatTime(decision.Release.CreatedAt), action, decision.Reason)
+	}
+
+	writer.Flush()
+}
+
+func (e *pruneFailedError) Error() string {
+	message := fmt.Sprintf("%d of %d releases could not be deleted", e.failed, e.total)
+	return message
+}
+
+func (e *pruneFailedError) ExitCode() int {
+	return 74
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestPlanPrune(test *testing.T) {
+	now := time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	newRelease := func(tag string, draft bool, preRelease bool, age time.Duration) *gitHubRelease {
+		createdAt := now.Add(-age)
+		return &gitHubRelease{TagName: &tag, Draft: &draft, PreRelease: &preRelease, CreatedAt: &createdAt}
+	}
+
+	day := 24 * time.Hour
+
+	releases := []*gitHubRelease{
+		newRelease("v1.0.0", false, false, 30*day),
+		newRelease("nightly-0830", false, true, 2*day),
+		newRelease("v1.2.0", false, false, 5*day),
+		newRelease("nightly-0801", false, true, 31*day),
+		newRelease("v1.1.0", false, false, 20*day),
+		newRelease("v1.3.0", true, false, day),
+		newRelease("v0.9.0-lts", false, false, 60*day),
+	}
+
+	policy := &retentionPolicy{
+		KeepStable:         2,
+		KeepPreReleasesFor: 7 * day,
+		Protect:            regexp.MustCompile(`-lts$`),
+		Now:                now,
+	}
+
+	expected := []struct {
+		tag    string
+		delete bool
+	}{
+		{"v1.3.0", false},
+		{"nightly-0830", false},
+		{"v1.2.0", false},
+		{"v1.1.0", false},
+		{"v1.0.0", true},
+		{"nightly-0801", true},
+		{"v0.9.0-lts", false},
+	}
+
+	decisions := planPrune(releases, policy)
+
+	if len(decisions) != len(expected) {
+		test.Fatalf("Expected %d decisions but got %d", len(expected), len(decisions))
+	}
+
+	for i, decision := range decisions {
+		if *decision.Release.TagName != expected[i].tag || decision.Delete != expected[i].delete {
+			test.Fatalf("Expected %s to be deleted: %t but got %s: %t (%s)", expected[i].tag, expected[i].delete, *decision.Release.TagName, decision.Delete, decision.Reason)
+		}
+	}
+
+	// Without any rules everything is kept
+	for _, decision := range planPrune(releases, &retentionPolicy{KeepStable: -1, Now: now}) {
+		if decision.Delete {
+			test.Fatalf("Expected %s to be kept", *decision.Release.TagName)
+		}
+	}
+}
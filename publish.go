@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"path"
+	"strings"
+)
+
+// publishChecks are what a draft release has to satisfy before
+// publish-release publishes it
+type publishChecks struct {
+	// RequiredAssets are asset names or glob patterns that each have to
+	// match at least one asset of the release
+	RequiredAssets []string
+}
+
+type releaseIncompleteError struct {
+	tag      string
+	problems []string
+}
+
+func newPublishChecks(ctx *cli.Context) (*publishChecks, error) {
+	checks := &publishChecks{}
+
+	for _, pattern := range strings.Split(ctx.String("require-assets"), ",") {
+		pattern = strings.TrimSpace(pattern)
+
+		if pattern == "" {
+			continue
+		}
+
+		_, err := path.Match(pattern, "")
+
+		if err != nil {
+			reason := fmt.Sprintf("\"%s\" is not a valid glob pattern: %v", pattern, err)
+			return nil, &badArgumentError{argument: "--require-assets", reason: reason}
+		}
+
+		checks.RequiredAssets = append(checks.RequiredAssets, pattern)
+	}
+
+	return checks, nil
+}
+
+// checkReleaseComplete returns what stops the release with the given assets
+// from being published, if anything: empty notes, assets that didn't finish
+// uploading and required assets that are missing
+func checkReleaseComplete(release *gitHubRelease, assets []*gitHubAsset, checks *publishChecks) []string {
+	var problems []string
+
+	if strings.TrimSpace(stringValue(release.Body)) == "" {
+		problems = append(problems, "the release notes are empty")
+	}
+
+	for _, asset := range assets {
+		if state := stringValue(asset.State); state != string(assetUploaded) {
+			problems = append(problems, fmt.Sprintf("asset %s is %s rather than uploaded", stringValue(asset.Name), state))
+		}
+	}
+
+	for _, pattern := range checks.RequiredAssets {
+		found := false
+
+		for _, asset := range assets {
+			if matched, _ := path.Match(pattern, stringValue(asset.Name)); matched {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("no asset matches %s", pattern))
+		}
+	}
+
+	return problems
+}
+
+// checkPublishable lists the assets of the release and checks it is complete
+func checkPublishable(netCtx context.Context, repo *gitHubRepo, release *gitHubRelease, checks *publishChecks, gitHubToken string) error {
+	assets, err := repo.ListReleaseAssets(netCtx, *release.ID, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	problems := checkReleaseComplete(release, assets, checks)
+
+	if len(problems) > 0 {
+		return &releaseIncompleteError{tag: stringValue(release.TagName), problems: problems}
+	}
+
+	return nil
+}
+
+func (e *releaseIncompleteError) Error() string {
+	message := fmt.Sprintf("Refusing to publish the release for %s:\n\t- %s", e.tag, strings.Join(e.problems, "\n\t- "))
+	return message
+}
+
+func (e *releaseIncompleteError) ExitCode() int {
+	return 65
+}
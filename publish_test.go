@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/google/go-github/github"
+	"reflect"
+	"testing"
+)
+
+func TestCheckReleaseComplete(test *testing.T) {
+	notes := "Fixes asset names."
+	release := &gitHubRelease{TagName: github.String("v1.1.0"), Body: &notes}
+
+	assets := []*gitHubAsset{
+		{Name: github.String("grease-linux-amd64.tar.gz"), State: github.String("uploaded")},
+		{Name: github.String("grease-darwin-amd64.tar.gz"), State: github.String("uploaded")},
+	}
+
+	checks := &publishChecks{RequiredAssets: []string{"grease-*.tar.gz", "grease-darwin-amd64.tar.gz"}}
+
+	problems := checkReleaseComplete(release, assets, checks)
+
+	if len(problems) > 0 {
+		test.Fatalf("Did not expect any problems but got %q", problems)
+	}
+}
+
+func TestCheckReleaseCompleteProblems(test *testing.T) {
+	notes := " \n"
+	release := &gitHubRelease{TagName: github.String("v1.1.0"), Body: &notes}
+
+	assets := []*gitHubAsset{
+		{Name: github.String("grease-linux-amd64.tar.gz"), State: github.String("uploaded")},
+		{Name: github.String("grease-darwin-amd64.tar.gz"), State: github.String("new")},
+	}
+
+	checks := &publishChecks{RequiredAssets: []string{"grease-*.tar.gz", "checksums.txt"}}
+
+	problems := checkReleaseComplete(release, assets, checks)
+
+	expected := []string{
+		"the release notes are empty",
+		"asset grease-darwin-amd64.tar.gz is new rather than uploaded",
+		"no asset matches checksums.txt",
+	}
+
+	if !reflect.DeepEqual(problems, expected) {
+		test.Fatalf("Expected problems %q but got %q", expected, problems)
+	}
+}
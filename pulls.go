@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"strings"
+)
+
+// notesSection is a heading release notes generated from pull requests are
+// grouped under. A pull request goes in the first section that has one of
+// its labels.
+type notesSection struct {
+	Title  string   `yaml:"title"`
+	Labels []string `yaml:"labels"`
+}
+
+// defaultNotesSections are used when the configuration file doesn't have a
+// notes-sections key
+var defaultNotesSections = []notesSection{
+	{Title: "Breaking", Labels: []string{"breaking", "breaking change"}},
+	{Title: "Features", Labels: []string{"feature", "enhancement"}},
+	{Title: "Fixes", Labels: []string{"bug", "fix"}},
+}
+
+// otherNotesSectionTitle is the heading for pull requests that don't match
+// any of the sections
+const otherNotesSectionTitle = "Other Changes"
+
+// configuredNotesSections reads the sections from the notes-sections key of
+// the configuration file, like:
+//
+//	notes-sections:
+//	  - title: Features
+//	    labels: [feature, enhancement]
+func configuredNotesSections(ctx *cli.Context) ([]notesSection, error) {
+	config := commandConfigFile(ctx)
+
+	if config == nil {
+		return defaultNotesSections, nil
+	}
+
+	var settings struct {
+		Sections []notesSection `yaml:"notes-sections"`
+	}
+
+	err := config.decode(&settings)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(settings.Sections) == 0 {
+		return defaultNotesSections, nil
+	}
+
+	return settings.Sections, nil
+}
+
+// mergedPullRequests finds the pull requests that were merged as one of the
+// commits in the comparison. Candidates are found by searching for pull
+// requests merged between the dates of the first and last commits, and then
+// checked against the commits so that pull requests merged into other
+// branches are left out. When GitHub truncated the comparison the check
+// can't be made and every candidate is kept.
+func mergedPullRequests(netCtx context.Context, repo *gitHubRepo, comparison *gitHubComparison, gitHubToken string) ([]*gitHubPullRequest, error) {
+	if len(comparison.Commits) == 0 {
+		return nil, nil
+	}
+
+	since := comparison.Commits[0].Date
+
+	if comparison.BaseCommit != nil && comparison.BaseCommit.Date != nil {
+		since = comparison.BaseCommit.Date
+	}
+
+	until := comparison.Commits[len(comparison.Commits)-1].Date
+
+	if since == nil || until == nil {
+		return nil, fmt.Errorf("GitHub did not return the dates of the commits to compare")
+	}
+
+	candidates, err := repo.SearchMergedPullRequests(netCtx, *since, *until, gitHubToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comparison.TotalCommits > len(comparison.Commits) {
+		return candidates, nil
+	}
+
+	commits := make(map[string]bool)
+
+	for _, commit := range comparison.Commits {
+		if commit.SHA != nil {
+			commits[*commit.SHA] = true
+		}
+	}
+
+	var pullRequests []*gitHubPullRequest
+
+	for _, pullRequest := range candidates {
+		sha, err := repo.GetPullRequestMergeCommitSHA(netCtx, *pullRequest.Number, gitHubToken)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if commits[sha] {
+			pullRequests = append(pullRequests, pullRequest)
+		}
+	}
+
+	return pullRequests, nil
+}
+
+// renderPullRequestNotes renders the pull requests as Markdown lists grouped
+// by section, linking to each pull request and its author
+func renderPullRequestNotes(base string, pullRequests []*gitHubPullRequest, sections []notesSection) string {
+	grouped := make([][]*gitHubPullRequest, len(sections)+1)
+
+	for _, pullRequest := range pullRequests {
+		index := notesSectionIndex(pullRequest, sections)
+		grouped[index] = append(grouped[index], pullRequest)
+	}
+
+	var notes bytes.Buffer
+
+	fmt.Fprintf(&notes, "## Changes since %s\n", base)
+
+	if len(pullRequests) == 0 {
+		notes.WriteString("\nNo changes.\n")
+	}
+
+	for i, group := range grouped {
+		if len(group) == 0 {
+			continue
+		}
+
+		title := otherNotesSectionTitle
+
+		if i < len(sections) {
+			title = sections[i].Title
+		}
+
+		fmt.Fprintf(&notes, "\n### %s\n\n", title)
+
+		for _, pullRequest := range group {
+			fmt.Fprintf(&notes, "- %s ([#%d](%s)) by [@%s](%s)\n",
+				stringValue(pullRequest.Title), intValue(pullRequest.Number), stringValue(pullRequest.HTMLURL),
+				stringValue(pullRequest.Author), stringValue(pullRequest.AuthorURL))
+		}
+	}
+
+	return notes.String()
+}
+
+// notesSectionIndex returns the index of the first section with one of the
+// pull request's labels, or len(sections) if there is none
+func notesSectionIndex(pullRequest *gitHubPullRequest, sections []notesSection) int {
+	for i, section := range sections {
+		for _, sectionLabel := range section.Labels {
+			for _, label := range pullRequest.Labels {
+				if strings.EqualFold(label, sectionLabel) {
+					return i
+				}
+			}
+		}
+	}
+
+	return len(sections)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+
+	return *value
+}
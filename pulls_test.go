@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRenderPullRequestNotes(test *testing.T) {
+	newPullRequest := func(number int, title string, labels ...string) *gitHubPullRequest {
+		url := "https://github.com/timberio/grease/pull/" + title
+		author := "octocat"
+		authorURL := "https://github.com/octocat"
+
+		return &gitHubPullRequest{
+			Number:    &number,
+			Title:     &title,
+			HTMLURL:   &url,
+			Author:    &author,
+			AuthorURL: &authorURL,
+			Labels:    labels,
+		}
+	}
+
+	pullRequests := []*gitHubPullRequest{
+		newPullRequest(3, "docs", "documentation"),
+		newPullRequest(2, "fix", "Bug"),
+		newPullRequest(1, "feature", "enhancement", "breaking"),
+	}
+
+	expected := "## Changes since v1.0.0\n" +
+		"\n### Breaking\n\n" +
+		"- feature ([#1](https://github.com/timberio/grease/pull/feature)) by [@octocat](https://github.com/octocat)\n" +
+		"\n### Fixes\n\n" +
+		"- fix ([#2](https://github.com/timberio/grease/pull/fix)) by [@octocat](https://github.com/octocat)\n" +
+		"\n### Other Changes\n\n" +
+		"- docs ([#3](https://github.com/timberio/grease/pull/docs)) by [@octocat](https://github.com/octocat)\n"
+
+	notes := renderPullRequestNotes("v1.0.0", pullRequests, defaultNotesSections)
+
+	if notes != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, notes)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const retryBaseDelay = time.Second
+const retryMaxDelay = 30 * time.Second
+
+// retryPolicy controls how calls to the GitHub API are retried when they
+// fail for reasons that are likely to be temporary: server errors, network
+// errors and rate limiting.
+type retryPolicy struct {
+	MaxRetries int
+	Timeout    time.Duration
+}
+
+type retryTimeoutError struct {
+	wait    time.Duration
+	timeout time.Duration
+	err     error
+}
+
+// do calls the given function until it succeeds, returns an error that
+// should not be retried, or the policy runs out of retries or time. The
+// attempt number, starting at 0, is passed to the function.
+//
+// Calls that are not idempotent, like creating a release, are only retried
+// when GitHub rejected them because of rate limiting, since in that case we
+// know the request was never processed.
+func (policy *retryPolicy) do(ctx context.Context, idempotent bool, call func(attempt int) error) error {
+	if policy == nil {
+		return call(0)
+	}
+
+	deadline := time.Now().Add(policy.Timeout)
+
+	for attempt := 0; ; attempt++ {
+		err := call(attempt)
+
+		if err == nil || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		wait, retry := retryDelay(err, attempt, idempotent)
+
+		if !retry {
+			return err
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return &retryTimeoutError{wait: wait, timeout: policy.Timeout, err: err}
+		}
+
+		// The notice goes to stderr so it doesn't end up in the middle of
+		// JSON output or the buffered output of parallel uploads
+		fmt.Fprintf(os.Stderr, "Request failed (%v); retrying in %s (attempt %d of %d)\n", err, wait, attempt+2, policy.MaxRetries+1)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryDelay decides whether err is worth retrying and, if so, how long to
+// wait before doing so
+func retryDelay(err error, attempt int, idempotent bool) (time.Duration, bool) {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		wait := e.Rate.Reset.Time.Sub(time.Now()) + time.Second
+
+		if wait < 0 {
+			wait = backoffDelay(attempt)
+		}
+
+		return wait, true
+	case *github.AbuseRateLimitError:
+		if e.RetryAfter != nil {
+			return *e.RetryAfter, true
+		}
+
+		return backoffDelay(attempt), true
+	case *github.ErrorResponse:
+		if !idempotent || e.Response == nil || e.Response.StatusCode < http.StatusInternalServerError {
+			return 0, false
+		}
+
+		if wait, ok := retryAfterHeader(e.Response); ok {
+			return wait, true
+		}
+
+		return backoffDelay(attempt), true
+	case net.Error:
+		if !idempotent {
+			return 0, false
+		}
+
+		return backoffDelay(attempt), true
+	}
+
+	return 0, false
+}
+
+// backoffDelay doubles the delay with every attempt, up to retryMaxDelay,
+// and picks a random point in the upper half of it so that concurrent
+// clients don't retry in lockstep
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+
+	if attempt < 16 {
+		delay = retryBaseDelay << uint(attempt)
+	}
+
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func retryAfterHeader(response *http.Response) (time.Duration, bool) {
+	value := response.Header.Get("Retry-After")
+
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(time.Now()), true
+	}
+
+	return 0, false
+}
+
+func (e *retryTimeoutError) Error() string {
+	message := fmt.Sprintf("Giving up since waiting another %s would exceed the retry timeout of %s: %v", e.wait, e.timeout, e.err)
+	return message
+}
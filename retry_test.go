@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/google/go-github/github"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelayServerError(test *testing.T) {
+	response := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+	err := &github.ErrorResponse{Response: response}
+
+	_, retry := retryDelay(err, 0, true)
+
+	if !retry {
+		test.Fatalf("Expected a 502 to be retried for an idempotent call")
+	}
+
+	_, retry = retryDelay(err, 0, false)
+
+	if retry {
+		test.Fatalf("Did not expect a 502 to be retried for a non-idempotent call")
+	}
+}
+
+func TestRetryDelayRetryAfter(test *testing.T) {
+	response := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	response.Header.Set("Retry-After", "7")
+	err := &github.ErrorResponse{Response: response}
+
+	wait, retry := retryDelay(err, 0, true)
+
+	if !retry || wait != 7*time.Second {
+		test.Fatalf("Expected to retry after 7s but got %s (retry: %t)", wait, retry)
+	}
+}
+
+func TestRetryDelayClientError(test *testing.T) {
+	response := &http.Response{StatusCode: http.StatusUnprocessableEntity, Header: http.Header{}}
+	err := &github.ErrorResponse{Response: response}
+
+	_, retry := retryDelay(err, 0, true)
+
+	if retry {
+		test.Fatalf("Did not expect a 422 to be retried")
+	}
+}
+
+func TestRetryDelayAbuseRateLimit(test *testing.T) {
+	retryAfter := 42 * time.Second
+	err := &github.AbuseRateLimitError{RetryAfter: &retryAfter}
+
+	wait, retry := retryDelay(err, 0, false)
+
+	if !retry || wait != retryAfter {
+		test.Fatalf("Expected to retry after %s but got %s (retry: %t)", retryAfter, wait, retry)
+	}
+}
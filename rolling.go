@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/net/context"
+	"strings"
+)
+
+// rollingAssetPrefix returns the prefix new assets of a rolling release are
+// uploaded under until the old ones have been deleted, like "tmp-566e0e8-"
+func rollingAssetPrefix(sha string) string {
+	return fmt.Sprintf("tmp-%s-", shortSHA(sha))
+}
+
+// renderRollingNotes describes the commit a rolling release was moved to,
+// followed by any notes passed on the command line
+func renderRollingNotes(tag string, commitish string, commit *gitHubCommit, notes *string) string {
+	var body bytes.Buffer
+
+	sha := stringValue(commit.SHA)
+	reference := shortSHA(sha)
+
+	if commit.HTMLURL != nil {
+		reference = fmt.Sprintf("[%s](%s)", shortSHA(sha), *commit.HTMLURL)
+	}
+
+	fmt.Fprintf(&body, "The %s release is rebuilt continuously. It was last updated to %s from %s", tag, reference, commitish)
+
+	if commit.Date != nil {
+		fmt.Fprintf(&body, " on %s", commit.Date.UTC().Format("2006-01-02 15:04 MST"))
+	}
+
+	body.WriteString(".\n")
+
+	if commit.Message != nil {
+		subject := strings.TrimSpace(strings.SplitN(*commit.Message, "\n", 2)[0])
+		fmt.Fprintf(&body, "\n> %s\n", subject)
+	}
+
+	if notes != nil && *notes != "" {
+		fmt.Fprintf(&body, "\n%s\n", strings.TrimSpace(*notes))
+	}
+
+	return body.String()
+}
+
+// deleteRollingAssets removes the assets uploaded under prefix. It is used to
+// clean up when some of the new assets failed to upload, leaving the old
+// assets in place.
+func deleteRollingAssets(netCtx context.Context, repo *gitHubRepo, releaseId int, prefix string, gitHubToken string) error {
+	assets, err := repo.ListReleaseAssets(netCtx, releaseId, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	for _, asset := range assets {
+		if strings.HasPrefix(*asset.Name, prefix) {
+			err = repo.DeleteReleaseAsset(netCtx, *asset.ID, gitHubToken)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// swapRollingAssets replaces the assets of a rolling release with the ones
+// uploaded under prefix: every other asset is deleted and the new ones are
+// renamed to drop the prefix. Downloads only fail for the short time between
+// an old asset being deleted and its replacement being renamed.
+func swapRollingAssets(netCtx context.Context, repo *gitHubRepo, releaseId int, prefix string, gitHubToken string, debug bool) error {
+	assets, err := repo.ListReleaseAssets(netCtx, releaseId, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	var uploaded []*gitHubAsset
+
+	for _, asset := range assets {
+		if strings.HasPrefix(*asset.Name, prefix) {
+			uploaded = append(uploaded, asset)
+			continue
+		}
+
+		if debug {
+			fmt.Printf("Deleting old asset %s (id: %d)\n", *asset.Name, *asset.ID)
+		}
+
+		err = repo.DeleteReleaseAsset(netCtx, *asset.ID, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, asset := range uploaded {
+		name := strings.TrimPrefix(*asset.Name, prefix)
+
+		if debug {
+			fmt.Printf("Renaming asset %s to %s\n", *asset.Name, name)
+		}
+
+		err = repo.RenameReleaseAsset(netCtx, *asset.ID, name, gitHubToken)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
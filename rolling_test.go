@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderRollingNotes(test *testing.T) {
+	sha := "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	message := "Fix asset names\n\nAssets were uploaded with their full path."
+	url := "https://github.com/timberio/grease/commit/566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"
+	date := time.Date(2017, 8, 23, 15, 4, 0, 0, time.UTC)
+	notes := "Built by CI.\n"
+
+	commit := &gitHubCommit{SHA: &sha, Message: &message, HTMLURL: &url, Date: &date}
+
+	expected := "The nightly release is rebuilt continuously. It was last updated to " +
+		"[566e0e8](https://github.com/timberio/grease/commit/566e0e80a473581d05bcdf8a0cddad97ea2fc6c2) " +
+		"from master on 2017-08-23 15:04 UTC.\n" +
+		"\n> Fix asset names\n" +
+		"\nBuilt by CI.\n"
+
+	body := renderRollingNotes("nightly", "master", commit, &notes)
+
+	if body != expected {
+		test.Fatalf("Expected notes to be %q but got %q", expected, body)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultMaxPublishWait is how far ahead --publish-at can be unless --max-wait
+// says otherwise
+const defaultMaxPublishWait = 24 * time.Hour
+
+// publishSchedule is when publish-release publishes an embargoed release
+type publishSchedule struct {
+	At time.Time
+}
+
+type publishWaitCancelledError struct {
+	tag string
+	at  time.Time
+}
+
+type publishNotVerifiedError struct {
+	tag string
+}
+
+// newPublishSchedule reads --publish-at and --max-wait. It returns nil when
+// --publish-at wasn't passed so the release is published right away. A time
+// further away than --max-wait is refused up front rather than after waiting
+// for the CI job to time out.
+func newPublishSchedule(ctx *cli.Context, now time.Time) (*publishSchedule, error) {
+	value := ctx.String("publish-at")
+
+	if value == "" {
+		if ctx.IsSet("max-wait") {
+			return nil, &badArgumentError{argument: "--max-wait", reason: "can only be used with --publish-at"}
+		}
+
+		return nil, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		reason := fmt.Sprintf("expected an RFC 3339 time like 2017-08-23T15:04:05Z but found \"%s\"", value)
+		return nil, &badArgumentError{argument: "--publish-at", reason: reason}
+	}
+
+	maxWait := ctx.Duration("max-wait")
+
+	if maxWait <= 0 {
+		reason := fmt.Sprintf("expected a positive duration but found %s", maxWait)
+		return nil, &badArgumentError{argument: "--max-wait", reason: reason}
+	}
+
+	if wait := at.Sub(now); wait > maxWait {
+		reason := fmt.Sprintf("%s is %s away, which is longer than --max-wait of %s", value, roundToSecond(wait), maxWait)
+		return nil, &badArgumentError{argument: "--publish-at", reason: reason}
+	}
+
+	return &publishSchedule{At: at}, nil
+}
+
+// waitUntil blocks until at or until netCtx is done, whichever comes first.
+// When out isn't nil, the time left is written to it every so often.
+func waitUntil(netCtx context.Context, at time.Time, out io.Writer) error {
+	for {
+		remaining := at.Sub(time.Now())
+
+		if remaining <= 0 {
+			return nil
+		}
+
+		if out != nil {
+			fmt.Fprintf(out, "Publishing in %s\n", roundToSecond(remaining))
+		}
+
+		select {
+		case <-netCtx.Done():
+			return netCtx.Err()
+		case <-time.After(countdownInterval(remaining)):
+		}
+	}
+}
+
+// countdownInterval is how long to wait before reporting the time left again.
+// Reports get more frequent as the time gets closer, and the last wait ends
+// exactly on time.
+func countdownInterval(remaining time.Duration) time.Duration {
+	interval := 10 * time.Second
+
+	switch {
+	case remaining > time.Hour:
+		interval = 15 * time.Minute
+	case remaining > 10*time.Minute:
+		interval = 5 * time.Minute
+	case remaining > time.Minute:
+		interval = time.Minute
+	}
+
+	if interval > remaining {
+		interval = remaining
+	}
+
+	return interval
+}
+
+// cancelOnSignal returns a context that is cancelled when grease is
+// interrupted or terminated, so a long wait can stop cleanly
+func cancelOnSignal(netCtx context.Context) (context.Context, func()) {
+	netCtx, cancel := context.WithCancel(netCtx)
+	signals := make(chan os.Signal, 1)
+
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-netCtx.Done():
+		}
+	}()
+
+	return netCtx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+func roundToSecond(duration time.Duration) time.Duration {
+	return duration - duration%time.Second
+}
+
+func (e *publishWaitCancelledError) Error() string {
+	message := fmt.Sprintf("Stopped waiting to publish the release for %s at %s; it is still a draft", e.tag, e.at.Format(time.RFC3339))
+	return message
+}
+
+func (e *publishWaitCancelledError) ExitCode() int {
+	return 1
+}
+
+func (e *publishNotVerifiedError) Error() string {
+	message := fmt.Sprintf("GitHub accepted publishing the release for %s but it is still a draft", e.tag)
+	return message
+}
+
+func (e *publishNotVerifiedError) ExitCode() int {
+	return 74
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"golang.org/x/net/context"
+	"testing"
+	"time"
+)
+
+func TestCountdownInterval(test *testing.T) {
+	expected := map[time.Duration]time.Duration{
+		3 * time.Hour:    15 * time.Minute,
+		30 * time.Minute: 5 * time.Minute,
+		5 * time.Minute:  time.Minute,
+		45 * time.Second: 10 * time.Second,
+		3 * time.Second:  3 * time.Second,
+	}
+
+	for remaining, interval := range expected {
+		if actual := countdownInterval(remaining); actual != interval {
+			test.Fatalf("Expected to wait %s with %s left but got %s", interval, remaining, actual)
+		}
+	}
+}
+
+func TestWaitUntil(test *testing.T) {
+	at := time.Now().Add(50 * time.Millisecond)
+
+	err := waitUntil(context.Background(), at, nil)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if time.Now().Before(at) {
+		test.Fatalf("Expected to wait until %s", at)
+	}
+}
+
+func TestWaitUntilCancelled(test *testing.T) {
+	netCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := waitUntil(netCtx, time.Now().Add(time.Hour), nil)
+
+	if err != context.DeadlineExceeded {
+		test.Fatalf("Expected the wait to be cancelled but got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semanticVersionPattern matches a semantic version as defined by
+// https://semver.org, optionally prefixed with a "v" as is common for tags
+var semanticVersionPattern = regexp.MustCompile(`^(v?)(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` +
+	`(?:-((?:0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// semanticVersion is a parsed version tag like v1.2.3-rc.1+build.5
+type semanticVersion struct {
+	// Prefix is "v" if the tag had one, so the version can be turned back
+	// into the same tag
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+	Build      string
+}
+
+type semanticVersionError struct {
+	version string
+}
+
+func parseSemanticVersion(tag string) (*semanticVersion, error) {
+	matches := semanticVersionPattern.FindStringSubmatch(tag)
+
+	if matches == nil {
+		return nil, &semanticVersionError{version: tag}
+	}
+
+	// The pattern only lets through digits, so these can only fail by
+	// overflowing
+	major, err := strconv.Atoi(matches[2])
+
+	if err != nil {
+		return nil, &semanticVersionError{version: tag}
+	}
+
+	minor, err := strconv.Atoi(matches[3])
+
+	if err != nil {
+		return nil, &semanticVersionError{version: tag}
+	}
+
+	patch, err := strconv.Atoi(matches[4])
+
+	if err != nil {
+		return nil, &semanticVersionError{version: tag}
+	}
+
+	version := &semanticVersion{
+		Prefix: matches[1],
+		Major:  major,
+		Minor:  minor,
+		Patch:  patch,
+		Build:  matches[6],
+	}
+
+	if matches[5] != "" {
+		version.PreRelease = strings.Split(matches[5], ".")
+	}
+
+	return version, nil
+}
+
+func (version *semanticVersion) String() string {
+	value := fmt.Sprintf("%s%d.%d.%d", version.Prefix, version.Major, version.Minor, version.Patch)
+
+	if len(version.PreRelease) > 0 {
+		value += "-" + strings.Join(version.PreRelease, ".")
+	}
+
+	if version.Build != "" {
+		value += "+" + version.Build
+	}
+
+	return value
+}
+
+func (version *semanticVersion) IsPreRelease() bool {
+	return len(version.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 when the version has lower, the same or higher
+// precedence than other. The prefix and build metadata don't count.
+func (version *semanticVersion) Compare(other *semanticVersion) int {
+	for _, difference := range []int{version.Major - other.Major, version.Minor - other.Minor, version.Patch - other.Patch} {
+		if difference != 0 {
+			return sign(difference)
+		}
+	}
+
+	// A pre-release comes before the release it leads up to
+	switch {
+	case !version.IsPreRelease() && !other.IsPreRelease():
+		return 0
+	case !version.IsPreRelease():
+		return 1
+	case !other.IsPreRelease():
+		return -1
+	}
+
+	for i := 0; i < len(version.PreRelease) && i < len(other.PreRelease); i++ {
+		if result := comparePreReleaseIdentifiers(version.PreRelease[i], other.PreRelease[i]); result != 0 {
+			return result
+		}
+	}
+
+	return sign(len(version.PreRelease) - len(other.PreRelease))
+}
+
+// comparePreReleaseIdentifiers compares numeric identifiers numerically and
+// the rest in ASCII order, with numeric identifiers coming first
+func comparePreReleaseIdentifiers(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(aNumber - bNumber)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+func sign(value int) int {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func (e *semanticVersionError) Error() string {
+	message := fmt.Sprintf("\"%s\" is not a semantic version like v1.2.3 or v1.2.3-rc.1", e.version)
+	return message
+}
+
+func (e *semanticVersionError) ExitCode() int {
+	return 65
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseSemanticVersion(test *testing.T) {
+	for _, tag := range []string{"v1.2.3", "0.1.0", "v2.0.0-beta.1", "v1.0.0-rc.1+build.5"} {
+		version, err := parseSemanticVersion(tag)
+
+		if err != nil {
+			test.Fatalf("Did not expect to receive error: %v", err)
+		}
+
+		if version.String() != tag {
+			test.Fatalf("Expected %s to be formatted as %s but got %s", tag, tag, version.String())
+		}
+	}
+
+	for _, tag := range []string{"1.2", "v01.2.3", "release-1.2.3", "v1.2.3-", "v1.2.3-01"} {
+		_, err := parseSemanticVersion(tag)
+
+		if err == nil {
+			test.Fatalf("Expected an error for %s", tag)
+		}
+	}
+}
+
+func TestCompareSemanticVersions(test *testing.T) {
+	// In increasing order of precedence, as in the example from the spec
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"v1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"v2.0.0",
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		lower, _ := parseSemanticVersion(ordered[i-1])
+		higher, _ := parseSemanticVersion(ordered[i])
+
+		if lower.Compare(higher) != -1 || higher.Compare(lower) != 1 {
+			test.Fatalf("Expected %s to come before %s", lower, higher)
+		}
+	}
+
+	withBuild, _ := parseSemanticVersion("v1.0.0+build.1")
+	withoutBuild, _ := parseSemanticVersion("1.0.0")
+
+	if withBuild.Compare(withoutBuild) != 0 {
+		test.Fatalf("Expected build metadata and prefix to be ignored")
+	}
+}
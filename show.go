@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// templateOutputPrefix starts an --output value that is a Go template, as in
+// --output 'template={{.TagName}}'
+const templateOutputPrefix = "template="
+
+// outputFormat is how a command that reports on releases prints them
+type outputFormat struct {
+	Name     string
+	Template *template.Template
+}
+
+// releaseSummary is a release as it is printed by show-release and
+// list-releases. Unlike gitHubRelease it holds plain values, so that
+// templates can test fields like Draft directly.
+type releaseSummary struct {
+	ID              int             `json:"id"`
+	TagName         string          `json:"tag_name"`
+	Name            string          `json:"name"`
+	TargetCommitish string          `json:"target_commitish"`
+	Body            string          `json:"body"`
+	Draft           bool            `json:"draft"`
+	PreRelease      bool            `json:"prerelease"`
+	Author          string          `json:"author"`
+	HTMLURL         string          `json:"html_url"`
+	CreatedAt       *time.Time      `json:"created_at"`
+	PublishedAt     *time.Time      `json:"published_at"`
+	Assets          []*assetSummary `json:"assets"`
+}
+
+type assetSummary struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	State         string `json:"state"`
+	ContentType   string `json:"content_type"`
+	Size          int    `json:"size"`
+	DownloadCount int    `json:"download_count"`
+	URL           string `json:"url"`
+	DownloadURL   string `json:"browser_download_url"`
+}
+
+// parseOutputFormat accepts one of the named formats a command supports, or
+// a Go template
+func parseOutputFormat(value string, names ...string) (*outputFormat, error) {
+	if strings.HasPrefix(value, templateOutputPrefix) {
+		parsed, err := template.New("--output").Option("missingkey=error").Parse(strings.TrimPrefix(value, templateOutputPrefix))
+
+		if err != nil {
+			return nil, &badArgumentError{argument: "--output", reason: err.Error()}
+		}
+
+		return &outputFormat{Name: "template", Template: parsed}, nil
+	}
+
+	for _, name := range names {
+		if value == name {
+			return &outputFormat{Name: name}, nil
+		}
+	}
+
+	reason := fmt.Sprintf("expected %s or %sTEMPLATE but found \"%s\"", strings.Join(names, ", "), templateOutputPrefix, value)
+	return nil, &badArgumentError{argument: "--output", reason: reason}
+}
+
+func newReleaseSummary(release *gitHubRelease) *releaseSummary {
+	summary := &releaseSummary{
+		ID:              intValue(release.ID),
+		TagName:         stringValue(release.TagName),
+		Name:            stringValue(release.Name),
+		TargetCommitish: stringValue(release.TargetCommitish),
+		Body:            stringValue(release.Body),
+		Draft:           boolValue(release.Draft),
+		PreRelease:      boolValue(release.PreRelease),
+		Author:          stringValue(release.Author),
+		HTMLURL:         stringValue(release.HTMLURL),
+		CreatedAt:       release.CreatedAt,
+		PublishedAt:     release.PublishedAt,
+		Assets:          make([]*assetSummary, 0, len(release.Assets)),
+	}
+
+	for _, asset := range release.Assets {
+		summary.Assets = append(summary.Assets, &assetSummary{
+			ID:            intValue(asset.ID),
+			Name:          stringValue(asset.Name),
+			State:         stringValue(asset.State),
+			ContentType:   stringValue(asset.ContentType),
+			Size:          intValue(asset.Size),
+			DownloadCount: intValue(asset.DownloadCount),
+			URL:           stringValue(asset.URL),
+			DownloadURL:   stringValue(asset.DownloadURL),
+		})
+	}
+
+	return summary
+}
+
+// writeJSON writes value as indented JSON followed by a newline
+func writeJSON(out io.Writer, value interface{}) error {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", encoded)
+
+	return err
+}
+
+// writeTemplate renders the template with value, adding a newline if the
+// template doesn't end with one
+func writeTemplate(out io.Writer, format *outputFormat, value interface{}) error {
+	var rendered bytes.Buffer
+
+	err := format.Template.Execute(&rendered, value)
+
+	if err != nil {
+		return &badArgumentError{argument: "--output", reason: err.Error()}
+	}
+
+	text := rendered.String()
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	_, err = io.WriteString(out, text)
+
+	return err
+}
+
+// writeReleaseSummary prints a release and its assets for people to read
+func writeReleaseSummary(out io.Writer, summary *releaseSummary) {
+	writer := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "Tag:\t%s\n", summary.TagName)
+	fmt.Fprintf(writer, "Name:\t%s\n", summary.Name)
+	fmt.Fprintf(writer, "ID:\t%d\n", summary.ID)
+	fmt.Fprintf(writer, "Commitish:\t%s\n", summary.TargetCommitish)
+	fmt.Fprintf(writer, "Draft:\t%t\n", summary.Draft)
+	fmt.Fprintf(writer, "Pre-release:\t%t\n", summary.PreRelease)
+	fmt.Fprintf(writer, "Author:\t%s\n", summary.Author)
+	fmt.Fprintf(writer, "Created:\t%s\n", formatTime(summary.CreatedAt))
+	fmt.Fprintf(writer, "Published:\t%s\n", formatTime(summary.PublishedAt))
+	fmt.Fprintf(writer, "URL:\t%s\n", summary.HTMLURL)
+	writer.Flush()
+
+	fmt.Fprintln(out, "-----Begin Release Notes-----")
+	fmt.Fprintln(out, summary.Body)
+	fmt.Fprintln(out, "------End Release Notes------")
+
+	if len(summary.Assets) == 0 {
+		fmt.Fprintln(out, "No assets")
+		return
+	}
+
+	writer = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "ASSET\tSIZE\tCONTENT TYPE\tSTATE\tDOWNLOADS\tURL")
+
+	for _, asset := range summary.Assets {
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%d\t%s\n", asset.Name, asset.Size, asset.ContentType, asset.State, asset.DownloadCount, asset.DownloadURL)
+	}
+
+	writer.Flush()
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return "-"
+	}
+
+	return value.Format(time.RFC3339)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseOutputFormat(test *testing.T) {
+	format, err := parseOutputFormat("json", "text", "json")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if format.Name != "json" || format.Template != nil {
+		test.Fatalf("Expected the json format but got %s", format.Name)
+	}
+
+	_, err = parseOutputFormat("yaml", "text", "json")
+
+	if err == nil {
+		test.Fatalf("Expected an error for an unknown format")
+	}
+
+	_, err = parseOutputFormat("template={{.TagName", "text", "json")
+
+	if err == nil {
+		test.Fatalf("Expected an error for a malformed template")
+	}
+}
+
+func TestWriteTemplate(test *testing.T) {
+	tag := "v1.0.0"
+	draft := false
+	name := "grease.tar.gz"
+	size := 1024
+
+	release := &gitHubRelease{
+		TagName: &tag,
+		Draft:   &draft,
+		Assets:  []*gitHubAsset{{Name: &name, Size: &size}},
+	}
+
+	format, err := parseOutputFormat("template={{.TagName}}{{if .Draft}} (draft){{end}}{{range .Assets}} {{.Name}}:{{.Size}}{{end}}", "text", "json")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	var out bytes.Buffer
+
+	err = writeTemplate(&out, format, newReleaseSummary(release))
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	expected := "v1.0.0 grease.tar.gz:1024\n"
+
+	if out.String() != expected {
+		test.Fatalf("Expected %q but got %q", expected, out.String())
+	}
+}
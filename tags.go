@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"time"
+)
+
+// annotatedTagOptions holds what goes into an annotated tag besides its name
+// and commit
+type annotatedTagOptions struct {
+	Message string
+	// Tagger is nil when no tagger was passed, in which case GitHub uses the
+	// owner of the token
+	Tagger *gitHubTagger
+}
+
+type lowerVersionError struct {
+	version string
+	latest  string
+}
+
+type tagExistsError struct {
+	tag string
+}
+
+// releaseTagVersion parses the tag of a release as a semantic version. Tags
+// that aren't semantic versions are only an error under --require-semver;
+// otherwise the version is nil and the version checks are skipped.
+func releaseTagVersion(ctx *cli.Context, tag string) (*semanticVersion, error) {
+	version, err := parseSemanticVersion(tag)
+
+	if err != nil {
+		if ctx.Bool("require-semver") {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	return version, nil
+}
+
+// releasePreRelease resolves --pre-release and --no-pre-release, marking the
+// release as a pre-release when neither was passed and the tag is a
+// pre-release version like v2.0.0-beta.1
+func releasePreRelease(ctx *cli.Context, version *semanticVersion) (*bool, error) {
+	preRelease, err := toggleFlagValue(ctx, "pre-release", "no-pre-release")
+
+	if err != nil || preRelease != nil || version == nil || !version.IsPreRelease() {
+		return preRelease, err
+	}
+
+	if ctx.GlobalBool("debug") {
+		fmt.Printf("Tag %s is a pre-release version; marking the release as a pre-release\n", version)
+	}
+
+	detected := true
+
+	return &detected, nil
+}
+
+// checkVersionNotLower makes sure version is not lower than the latest
+// release, which is the highest version among the published releases that
+// aren't pre-releases. Releases whose tags aren't semantic versions are
+// ignored.
+func checkVersionNotLower(netCtx context.Context, repo *gitHubRepo, version *semanticVersion, gitHubToken string) error {
+	releases, err := repo.ListReleases(netCtx, gitHubToken)
+
+	if err != nil {
+		return err
+	}
+
+	var latest *semanticVersion
+
+	for _, release := range releases {
+		if release.TagName == nil || boolValue(release.Draft) || boolValue(release.PreRelease) {
+			continue
+		}
+
+		released, err := parseSemanticVersion(*release.TagName)
+
+		if err != nil || released.IsPreRelease() {
+			continue
+		}
+
+		if latest == nil || released.Compare(latest) > 0 {
+			latest = released
+		}
+	}
+
+	if latest != nil && version.Compare(latest) < 0 {
+		return &lowerVersionError{version: version.String(), latest: latest.String()}
+	}
+
+	return nil
+}
+
+// newAnnotatedTagOptions reads --annotate and the flags that go with it. It
+// returns nil when --annotate wasn't passed, leaving GitHub to create a
+// lightweight tag along with the release. The message defaults to the name
+// of the tag and is rendered as a template when templateCtx isn't nil.
+func newAnnotatedTagOptions(ctx *cli.Context, templateCtx *templateContext) (*annotatedTagOptions, error) {
+	if !ctx.Bool("annotate") {
+		for _, name := range []string{"tag-message", "tagger-name", "tagger-email"} {
+			if ctx.IsSet(name) {
+				return nil, &badArgumentError{argument: "--" + name, reason: "can only be used with --annotate"}
+			}
+		}
+
+		return nil, nil
+	}
+
+	message := ctx.String("tag-message")
+
+	if message == "" {
+		message = ctx.String("tag")
+	}
+
+	message, err := renderTemplate("--tag-message", message, templateCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := &annotatedTagOptions{Message: message}
+
+	name := ctx.String("tagger-name")
+	email := ctx.String("tagger-email")
+
+	if name == "" && email == "" {
+		return options, nil
+	}
+
+	if name == "" {
+		return nil, &missingRequiredArgumentError{argument: "--tagger-name"}
+	}
+
+	if email == "" {
+		return nil, &missingRequiredArgumentError{argument: "--tagger-email"}
+	}
+
+	now := time.Now()
+	options.Tagger = &gitHubTagger{Name: &name, Email: &email, Date: &now}
+
+	return options, nil
+}
+
+// createAnnotatedTag creates an annotated tag object for the commit that
+// commitish resolves to and a ref pointing at it. An annotated tag that
+// already points at the commit is kept, so a run that failed after creating
+// the tag can be repeated. It reports whether the tag was created.
+func createAnnotatedTag(netCtx context.Context, repo *gitHubRepo, tag string, commitish string, options *annotatedTagOptions, gitHubToken string, debug bool) (bool, error) {
+	sha, err := repo.GetCommitSHA(netCtx, commitish, gitHubToken)
+
+	if err != nil {
+		return false, err
+	}
+
+	ref, err := repo.FindTagRef(netCtx, tag, gitHubToken)
+
+	if err != nil {
+		return false, err
+	}
+
+	if ref != nil {
+		if stringValue(ref.ObjectType) != "tag" {
+			return false, &tagExistsError{tag: tag}
+		}
+
+		tagSHA, err := tagCommitSHA(netCtx, repo, ref, gitHubToken)
+
+		if err != nil {
+			return false, err
+		}
+
+		if tagSHA != sha {
+			return false, &tagExistsError{tag: tag}
+		}
+
+		fmt.Printf("Annotated tag %s already points at %s\n", tag, shortSHA(sha))
+
+		return false, nil
+	}
+
+	tagSHA, err := repo.CreateTagObject(netCtx, tag, options.Message, sha, options.Tagger, gitHubToken)
+
+	if err != nil {
+		return false, err
+	}
+
+	if debug {
+		fmt.Printf("Created tag object %s for commit %s\n", shortSHA(tagSHA), shortSHA(sha))
+	}
+
+	err = repo.CreateTagRef(netCtx, tag, tagSHA, gitHubToken)
+
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("Created annotated tag %s at %s\n", tag, shortSHA(sha))
+
+	return true, nil
+}
+
+// tagCommitSHA returns the SHA of the commit a tag points at. Lightweight
+// tags point at the commit directly, while annotated tags point at a tag
+// object that has to be resolved to its commit first.
+func tagCommitSHA(netCtx context.Context, repo *gitHubRepo, ref *gitHubRef, gitHubToken string) (string, error) {
+	if stringValue(ref.ObjectType) == "commit" {
+		return stringValue(ref.ObjectSHA), nil
+	}
+
+	return repo.GetCommitSHA(netCtx, stringValue(ref.Ref), gitHubToken)
+}
+
+func boolValue(value *bool) bool {
+	return value != nil && *value
+}
+
+func (e *lowerVersionError) Error() string {
+	message := fmt.Sprintf("Version %s is lower than the latest release %s; pass --force to create it anyway", e.version, e.latest)
+	return message
+}
+
+func (e *lowerVersionError) ExitCode() int {
+	return 65
+}
+
+func (e *tagExistsError) Error() string {
+	message := fmt.Sprintf("Tag %s already exists and is not an annotated tag for the same commit; --annotate only creates new tags", e.tag)
+	return message
+}
+
+func (e *tagExistsError) ExitCode() int {
+	return 65
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckVersionNotLower(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`[
+			{"tag_name": "v2.0.0", "draft": true},
+			{"tag_name": "v1.5.0-rc.1", "prerelease": true},
+			{"tag_name": "nightly"},
+			{"tag_name": "v1.4.0"},
+			{"tag_name": "v1.3.2"}
+		]`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+
+	for tag, lower := range map[string]bool{"v1.3.3": true, "v1.4.0-rc.1": true, "v1.4.1": false, "1.5.0-beta.1": false} {
+		version, _ := parseSemanticVersion(tag)
+
+		err := checkVersionNotLower(context.Background(), repo, version, "token")
+
+		if lower && err == nil {
+			test.Fatalf("Expected %s to be refused as lower than v1.4.0", tag)
+		}
+
+		if !lower && err != nil {
+			test.Fatalf("Did not expect to receive error: %v", err)
+		}
+	}
+}
+
+func TestTagCommitSHA(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/repos/timberio/grease/commits/refs/tags/v1.1.0" {
+			test.Fatalf("Did not expect a request for %s", request.URL.Path)
+		}
+
+		writer.Write([]byte("566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+
+	lightweight := &gitHubRef{
+		Ref:        github.String("refs/tags/v1.0.0"),
+		ObjectSHA:  github.String("a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c"),
+		ObjectType: github.String("commit"),
+	}
+
+	sha, err := tagCommitSHA(context.Background(), repo, lightweight, "token")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if sha != "a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c" {
+		test.Fatalf("Expected the lightweight tag's own SHA but got %s", sha)
+	}
+
+	annotated := &gitHubRef{
+		Ref:        github.String("refs/tags/v1.1.0"),
+		ObjectSHA:  github.String("f2a9c6d1e0b53c2ab85f1e7d3c4b6a9e8d7c6b5a"),
+		ObjectType: github.String("tag"),
+	}
+
+	sha, err = tagCommitSHA(context.Background(), repo, annotated, "token")
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if sha != "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2" {
+		test.Fatalf("Expected the annotated tag's commit SHA but got %s", sha)
+	}
+}
+
+func TestCreateAnnotatedTag(test *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		requests = append(requests, request.Method+" "+request.URL.Path+" "+strings.TrimSpace(string(body)))
+
+		switch request.Method + " " + request.URL.Path {
+		case "GET /repos/timberio/grease/git/refs/tags/v1.1.0":
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message": "Not Found"}`))
+		case "GET /repos/timberio/grease/commits/master":
+			writer.Write([]byte("566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"))
+		case "POST /repos/timberio/grease/git/tags":
+			writer.WriteHeader(http.StatusCreated)
+			writer.Write([]byte(`{"sha": "f2a9c6d1e0b53c2ab85f1e7d3c4b6a9e8d7c6b5a"}`))
+		case "POST /repos/timberio/grease/git/refs":
+			writer.WriteHeader(http.StatusCreated)
+			writer.Write([]byte(`{"ref": "refs/tags/v1.1.0"}`))
+		default:
+			test.Fatalf("Did not expect a request for %s %s", request.Method, request.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+	options := &annotatedTagOptions{Message: "Grease v1.1.0"}
+
+	created, err := createAnnotatedTag(context.Background(), repo, "v1.1.0", "master", options, "token", false)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if !created {
+		test.Fatalf("Expected the tag to be reported as created")
+	}
+
+	expected := []string{
+		"GET /repos/timberio/grease/commits/master ",
+		"GET /repos/timberio/grease/git/refs/tags/v1.1.0 ",
+		`POST /repos/timberio/grease/git/tags {"tag":"v1.1.0","message":"Grease v1.1.0","object":"566e0e80a473581d05bcdf8a0cddad97ea2fc6c2","type":"commit"}`,
+		`POST /repos/timberio/grease/git/refs {"ref":"refs/tags/v1.1.0","sha":"f2a9c6d1e0b53c2ab85f1e7d3c4b6a9e8d7c6b5a"}`,
+	}
+
+	if !reflect.DeepEqual(requests, expected) {
+		test.Fatalf("Expected requests %q but got %q", expected, requests)
+	}
+}
+
+func TestCreateAnnotatedTagExists(test *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != "GET" {
+			test.Fatalf("Did not expect a %s request for an existing tag", request.Method)
+		}
+
+		switch request.URL.Path {
+		case "/repos/timberio/grease/commits/master", "/repos/timberio/grease/commits/refs/tags/v1.1.0":
+			writer.Write([]byte("566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"))
+		case "/repos/timberio/grease/commits/refs/tags/v1.0.0":
+			writer.Write([]byte("a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c"))
+		case "/repos/timberio/grease/git/refs/tags/v1.1.0":
+			writer.Write([]byte(`{"ref": "refs/tags/v1.1.0", "object": {"sha": "f2a9c6d1e0b53c2ab85f1e7d3c4b6a9e8d7c6b5a", "type": "tag"}}`))
+		case "/repos/timberio/grease/git/refs/tags/v1.0.0":
+			writer.Write([]byte(`{"ref": "refs/tags/v1.0.0", "object": {"sha": "b7e3d2c1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e6d5", "type": "tag"}}`))
+		case "/repos/timberio/grease/git/refs/tags/nightly":
+			writer.Write([]byte(`{"ref": "refs/tags/nightly", "object": {"sha": "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2", "type": "commit"}}`))
+		default:
+			test.Fatalf("Did not expect a request for %s", request.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+	options := &annotatedTagOptions{Message: "Grease v1.1.0"}
+
+	// An annotated tag left behind for the same commit is used as it is
+	created, err := createAnnotatedTag(context.Background(), repo, "v1.1.0", "master", options, "token", false)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if created {
+		test.Fatalf("Did not expect the existing tag to be reported as created")
+	}
+
+	for _, tag := range []string{"v1.0.0", "nightly"} {
+		_, err = createAnnotatedTag(context.Background(), repo, tag, "master", options, "token", false)
+
+		if _, ok := err.(*tagExistsError); !ok {
+			test.Fatalf("Expected a tagExistsError for %s but got %v", tag, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateContext is what --name and the release notes are rendered with
+// when --template is passed. The commit and the assets are only looked up
+// when a template uses them, so templates that don't need them work without
+// a token or any files.
+type templateContext struct {
+	Tag     string
+	Version *templateVersion
+	Date    time.Time
+	Owner   string
+	Name    string
+	Env     map[string]string
+
+	netCtx      context.Context
+	repo        *gitHubRepo
+	head        string
+	gitHubToken string
+	assetsGlob  string
+	commit      string
+	assets      []*templateAsset
+}
+
+// templateVersion holds the parts of the tag when it is a semantic version
+type templateVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+}
+
+type templateAsset struct {
+	Name   string
+	Path   string
+	Size   int64
+	SHA256 string
+}
+
+func newTemplateContext(ctx *cli.Context, repo *gitHubRepo) *templateContext {
+	tag := ctx.String("tag")
+	head := ctx.String("target-commitish")
+
+	if head == "" {
+		head = tag
+	}
+
+	templateCtx := &templateContext{
+		Tag:         tag,
+		Date:        time.Now().UTC(),
+		Owner:       repo.Owner,
+		Name:        repo.Name,
+		Env:         make(map[string]string),
+		netCtx:      context.Background(),
+		repo:        repo,
+		head:        head,
+		gitHubToken: ctx.String("github-token"),
+		assetsGlob:  ctx.String("assets"),
+	}
+
+	if version, err := parseSemanticVersion(tag); err == nil {
+		templateCtx.Version = &templateVersion{
+			Major:      version.Major,
+			Minor:      version.Minor,
+			Patch:      version.Patch,
+			PreRelease: strings.Join(version.PreRelease, "."),
+			Build:      version.Build,
+		}
+	}
+
+	for _, variable := range os.Environ() {
+		parts := strings.SplitN(variable, "=", 2)
+
+		if len(parts) == 2 {
+			templateCtx.Env[parts[0]] = parts[1]
+		}
+	}
+
+	return templateCtx
+}
+
+// Commit returns the SHA of the commit the release points at
+func (templateCtx *templateContext) Commit() (string, error) {
+	if templateCtx.commit == "" {
+		sha, err := templateCtx.repo.GetCommitSHA(templateCtx.netCtx, templateCtx.head, templateCtx.gitHubToken)
+
+		if err != nil {
+			return "", err
+		}
+
+		templateCtx.commit = sha
+	}
+
+	return templateCtx.commit, nil
+}
+
+// Assets returns the files matched by --assets with their sizes and SHA-256
+// checksums
+func (templateCtx *templateContext) Assets() ([]*templateAsset, error) {
+	if templateCtx.assets != nil {
+		return templateCtx.assets, nil
+	}
+
+	paths, err := findFiles(templateCtx.assetsGlob)
+
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([]*templateAsset, 0, len(paths))
+
+	for _, assetPath := range paths {
+		asset, err := newTemplateAsset(assetPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, asset)
+	}
+
+	templateCtx.assets = assets
+
+	return assets, nil
+}
+
+func newTemplateAsset(assetPath string) (*templateAsset, error) {
+	file, err := os.Open(assetPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &templateAsset{
+		Name:   path.Base(assetPath),
+		Path:   assetPath,
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	return asset, nil
+}
+
+// renderTemplate renders text as a Go template when --template was passed
+// and returns it unchanged otherwise. Missing keys, like an unset
+// environment variable, are an error rather than rendering as "<no value>".
+func renderTemplate(argument string, text string, templateCtx *templateContext) (string, error) {
+	if templateCtx == nil {
+		return text, nil
+	}
+
+	parsed, err := template.New(argument).Option("missingkey=error").Parse(text)
+
+	if err != nil {
+		return "", &badArgumentError{argument: argument, reason: err.Error()}
+	}
+
+	var rendered bytes.Buffer
+
+	err = parsed.Execute(&rendered, templateCtx)
+
+	if err != nil {
+		return "", &badArgumentError{argument: argument, reason: err.Error()}
+	}
+
+	return rendered.String(), nil
+}
+
+// releaseTemplateContext returns the context to render templates with, or
+// nil if --template wasn't passed
+func releaseTemplateContext(ctx *cli.Context, repo *gitHubRepo) *templateContext {
+	if !ctx.Bool("template") {
+		return nil
+	}
+
+	return newTemplateContext(ctx, repo)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(test *testing.T) {
+	templateCtx := &templateContext{
+		Tag:     "v1.2.0-rc.1",
+		Version: &templateVersion{Major: 1, Minor: 2, Patch: 0, PreRelease: "rc.1"},
+		Date:    time.Date(2017, 8, 23, 12, 0, 0, 0, time.UTC),
+		Owner:   "timberio",
+		Name:    "grease",
+		Env:     map[string]string{"BUILD_NUMBER": "42"},
+		assets:  []*templateAsset{{Name: "grease.tar.gz", Size: 1024, SHA256: "abc123"}},
+	}
+
+	text := `{{.Tag}} - {{.Date.Format "2006-01-02"}} ({{.Version.Major}}.{{.Version.Minor}}, {{.Version.PreRelease}}) #{{.Env.BUILD_NUMBER}}` +
+		`{{range .Assets}} {{.Name}}:{{.Size}}:{{.SHA256}}{{end}}`
+	expected := "v1.2.0-rc.1 - 2017-08-23 (1.2, rc.1) #42 grease.tar.gz:1024:abc123"
+
+	rendered, err := renderTemplate("--name", text, templateCtx)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	if rendered != expected {
+		test.Fatalf("Expected %q but got %q", expected, rendered)
+	}
+
+	// Templates are only rendered with a context
+	rendered, err = renderTemplate("--name", "{{.Tag}}", nil)
+
+	if err != nil || rendered != "{{.Tag}}" {
+		test.Fatalf("Expected the text to be left alone but got %q (%v)", rendered, err)
+	}
+
+	for _, missing := range []string{"{{.Env.UNSET}}", "{{.Branch}}"} {
+		_, err = renderTemplate("--notes", missing, templateCtx)
+
+		if err == nil {
+			test.Fatalf("Expected an error for %s", missing)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"gopkg.in/urfave/cli.v1"
+	"net/http"
+	"os"
+)
+
+type unknownCommitishError struct {
+	commitish string
+}
+
+type tagMismatchError struct {
+	tag       string
+	tagSHA    string
+	commitish string
+	sha       string
+}
+
+// resolveTargetCommitish returns the commit-ish a release is created from,
+// which is the repository's default branch when COMMITISH was left out. The
+// default is stored back in the context so the notes and templates use it
+// too.
+func resolveTargetCommitish(netCtx context.Context, ctx *cli.Context, repo *gitHubRepo, gitHubToken string) (string, error) {
+	commitish := ctx.String("target-commitish")
+
+	if commitish != "" {
+		return commitish, nil
+	}
+
+	defaultBranch, err := repo.DefaultBranch(netCtx, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	if ctx.GlobalBool("debug") {
+		fmt.Printf("No commitish given; using the default branch %s\n", defaultBranch)
+	}
+
+	err = ctx.Set("target-commitish", defaultBranch)
+
+	if err != nil {
+		return "", err
+	}
+
+	return defaultBranch, nil
+}
+
+// verifyReleaseTarget checks a release is about to be created from the
+// commit that was meant before GitHub gets to create the tag. It resolves
+// commitish to the SHA of a commit, which is returned, and makes sure an
+// existing tag points at that same commit. A commit that isn't on the default
+// branch, which is usually a stale or mistyped branch, only gets a warning
+// since releasing from a maintenance branch is legitimate.
+func verifyReleaseTarget(netCtx context.Context, repo *gitHubRepo, tag string, commitish string, gitHubToken string, debug bool) (string, error) {
+	sha, err := repo.GetCommitSHA(netCtx, commitish, gitHubToken)
+
+	if isUnknownRefError(err) {
+		return "", &unknownCommitishError{commitish: commitish}
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if debug {
+		fmt.Printf("Commitish %s is commit %s\n", commitish, sha)
+	}
+
+	ref, err := repo.FindTagRef(netCtx, tag, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	if ref != nil {
+		tagSHA, err := tagCommitSHA(netCtx, repo, ref, gitHubToken)
+
+		if err != nil {
+			return "", err
+		}
+
+		if tagSHA != sha {
+			return "", &tagMismatchError{tag: tag, tagSHA: tagSHA, commitish: commitish, sha: sha}
+		}
+
+		if debug {
+			fmt.Printf("Tag %s already points at %s\n", tag, shortSHA(sha))
+		}
+	}
+
+	defaultBranch, err := repo.DefaultBranch(netCtx, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	if commitish == defaultBranch {
+		return sha, nil
+	}
+
+	// Comparing the default branch with the commit counts the commits that
+	// are only reachable from the commit
+	comparison, err := repo.CompareCommits(netCtx, defaultBranch, sha, gitHubToken)
+
+	if err != nil {
+		return "", err
+	}
+
+	if comparison.TotalCommits > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: commit %s (%s) is not on the default branch %s; it is %d commits ahead of it\n",
+			shortSHA(sha), commitish, defaultBranch, comparison.TotalCommits)
+	}
+
+	return sha, nil
+}
+
+// isUnknownRefError reports whether GitHub could not find the commit for a
+// ref. Depending on the ref, GitHub answers with either a 404 or a 422.
+func isUnknownRefError(err error) bool {
+	errorResponse, ok := err.(*github.ErrorResponse)
+
+	if !ok || errorResponse.Response == nil {
+		return false
+	}
+
+	switch errorResponse.Response.StatusCode {
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return true
+	}
+
+	return false
+}
+
+func (e *unknownCommitishError) Error() string {
+	message := fmt.Sprintf("Could not find a commit for %s; check the branch, tag or SHA is spelled right and has been pushed", e.commitish)
+	return message
+}
+
+func (e *unknownCommitishError) ExitCode() int {
+	return 65
+}
+
+func (e *tagMismatchError) Error() string {
+	message := fmt.Sprintf("Tag %s already exists and points at %s, but %s is %s", e.tag, shortSHA(e.tagSHA), e.commitish, shortSHA(e.sha))
+	return message
+}
+
+func (e *tagMismatchError) ExitCode() int {
+	return 65
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newVerifyTestRepo serves a repository whose default branch is main, where
+// the branch release-1.x is one commit ahead of main and the tag v1.0.0 points
+// at the head of main
+func newVerifyTestRepo() (*gitHubRepo, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/repos/timberio/grease":
+			writer.Write([]byte(`{"default_branch": "main"}`))
+		case "/repos/timberio/grease/commits/main":
+			writer.Write([]byte("566e0e80a473581d05bcdf8a0cddad97ea2fc6c2"))
+		case "/repos/timberio/grease/commits/release-1.x":
+			writer.Write([]byte("a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c"))
+		case "/repos/timberio/grease/commits/mian":
+			writer.WriteHeader(http.StatusUnprocessableEntity)
+			writer.Write([]byte(`{"message": "No commit found for SHA: mian"}`))
+		case "/repos/timberio/grease/git/refs/tags/v1.0.0":
+			writer.Write([]byte(`{"ref": "refs/tags/v1.0.0", "object": {"sha": "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2", "type": "commit"}}`))
+		case "/repos/timberio/grease/compare/main...a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c":
+			writer.Write([]byte(`{"total_commits": 1, "commits": [{"sha": "a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c"}]}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+
+	serverURL, _ := url.Parse(server.URL + "/")
+	client := github.NewClient(nil)
+	client.BaseURL = serverURL
+
+	repo := &gitHubRepo{Owner: "timberio", Name: "grease", client: client, clientToken: "token"}
+
+	return repo, server.Close
+}
+
+func TestVerifyReleaseTarget(test *testing.T) {
+	repo, closeServer := newVerifyTestRepo()
+	defer closeServer()
+
+	expected := map[string]string{
+		"main":        "566e0e80a473581d05bcdf8a0cddad97ea2fc6c2",
+		"release-1.x": "a4c4a3b08c4f2e33ee0ef6dc1b4ff3e8ed8a7f0c",
+	}
+
+	for commitish, expectedSHA := range expected {
+		sha, err := verifyReleaseTarget(context.Background(), repo, "v1.1.0", commitish, "token", false)
+
+		if err != nil {
+			test.Fatalf("Did not expect to receive error: %v", err)
+		}
+
+		if sha != expectedSHA {
+			test.Fatalf("Expected %s to resolve to %s but got %s", commitish, expectedSHA, sha)
+		}
+	}
+}
+
+func TestVerifyReleaseTargetExistingTag(test *testing.T) {
+	repo, closeServer := newVerifyTestRepo()
+	defer closeServer()
+
+	_, err := verifyReleaseTarget(context.Background(), repo, "v1.0.0", "main", "token", false)
+
+	if err != nil {
+		test.Fatalf("Did not expect to receive error: %v", err)
+	}
+
+	_, err = verifyReleaseTarget(context.Background(), repo, "v1.0.0", "release-1.x", "token", false)
+
+	if _, ok := err.(*tagMismatchError); !ok {
+		test.Fatalf("Expected a tagMismatchError but got %v", err)
+	}
+}
+
+func TestVerifyReleaseTargetUnknownCommitish(test *testing.T) {
+	repo, closeServer := newVerifyTestRepo()
+	defer closeServer()
+
+	_, err := verifyReleaseTarget(context.Background(), repo, "v1.1.0", "mian", "token", false)
+
+	if _, ok := err.(*unknownCommitishError); !ok {
+		test.Fatalf("Expected an unknownCommitishError but got %v", err)
+	}
+}